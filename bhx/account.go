@@ -2,21 +2,25 @@ package bhx
 
 import (
 	"bytes"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"time"
 )
 
 // Account contains name, public key, custom fieldset and signature
 type Account struct {
+	version   uint8
 	pub       PubKey
 	name      string
 	fields    map[string]string
 	timestamp uint32
+	seq       uint64  // update sequence number (version 2)
+	prev      Hash256 // previous version hash (version 2)
 	sign      SigData
 }
 
@@ -52,8 +56,52 @@ func (a *Account) IsSet(name string) bool {
 	return ok
 }
 
+// FieldNames returns sorted field names
+func (a *Account) FieldNames() []string { return a.sortedKeys() }
+
+// Account versions
+const (
+	AccountVersionLegacy = 0 // hash covers only the last field, see VerifyLegacy
+	AccountVersion1      = 1 // hash is merkle root over header and all fields
+	AccountVersion2      = 2 // like version 1, header has sequence and previous hash
+	AccountVersionLatest = AccountVersion2
+)
+
+// Account hash domain prefixes (merkle inner nodes use 0x01)
+var (
+	accountLeafPrefix   = []byte{0x00}
+	accountHeaderPrefix = []byte{0x02}
+	accountRootPrefix   = []byte{0x03}
+)
+
+// Account hash errors
+var (
+	ErrAccountVersion = errors.New("unsupported account version")
+	ErrAccountKey     = errors.New("account public key mismatch")
+	ErrFieldNotFound  = errors.New("account field not found")
+)
+
+// Version returns account version
+func (a *Account) Version() uint8 { return a.version }
+
+// Sequence returns account update number (0 for the first version)
+func (a *Account) Sequence() uint64 { return a.seq }
+
+// Prev returns hash of previous account version (zero for the first version)
+func (a *Account) Prev() Hash256 { return a.prev }
+
 // GetHash returns hash of all account data
 func (a *Account) GetHash() Hash256 {
+	if a.version == AccountVersionLegacy {
+		return a.legacyHash()
+	}
+
+	leaves, _ := a.fieldLeaves()
+	return accountRoot(a.headerHash(), MerkleRoot(leaves), len(leaves))
+}
+
+// legacyHash is version 0 hash, it's kept for verifying old signatures
+func (a *Account) legacyHash() Hash256 {
 	pubHash := Sha256H(a.pub[:])
 	nameHash := Sha256H([]byte(a.name))
 	ts := make([]byte, 4)
@@ -79,88 +127,279 @@ func (a *Account) GetHash() Hash256 {
 	return root
 }
 
-// Verify account signature
+// headerHash commits to version, public key, name, timestamp
+// and (since version 2) to sequence number and previous version hash
+func (a *Account) headerHash() Hash256 {
+	nameHash := Sha256H([]byte(a.name))
+	ts := make([]byte, 4)
+	PutUint32Le(ts, a.timestamp)
+	if a.version < AccountVersion2 {
+		return Sha256H(accountHeaderPrefix, []byte{a.version}, a.pub[:], nameHash[:], ts)
+	}
+
+	seq := make([]byte, 8)
+	PutUint64Le(seq, a.seq)
+	return Sha256H(accountHeaderPrefix, []byte{a.version}, a.pub[:], nameHash[:], ts, seq, a.prev[:])
+}
+
+// fieldLeaves returns field leaf hashes sorted by key hash and key hashes
+func (a *Account) fieldLeaves() (leaves, keys []Hash256) {
+	byKey := make(map[Hash256]Hash256, len(a.fields))
+	for k, v := range a.fields {
+		keyH := Sha256H([]byte(k))
+		byKey[keyH] = fieldLeaf(keyH, v)
+		keys = append(keys, keyH)
+	}
+
+	keys = SortHash256(keys)
+	leaves = make([]Hash256, len(keys))
+	for i, k := range keys {
+		leaves[i] = byKey[k]
+	}
+
+	return
+}
+
+func fieldLeaf(keyHash Hash256, value string) Hash256 {
+	valHash := Sha256H([]byte(value))
+	return Sha256H(accountLeafPrefix, keyHash[:], valHash[:])
+}
+
+func accountRoot(header, fieldsRoot Hash256, count int) Hash256 {
+	cnt := make([]byte, 4)
+	PutUint32Le(cnt, uint32(count))
+	return Sha256H(accountRootPrefix, header[:], fieldsRoot[:], cnt)
+}
+
+// FieldProof proves, that single field is committed by account hash,
+// so the field can be disclosed without other fields
+type FieldProof struct {
+	Header Hash256   // account header hash
+	Index  int       // field leaf index
+	Count  int       // fields count
+	Path   []Hash256 // merkle path from field leaf to fields root
+}
+
+// FieldProof returns proof for given field
+func (a *Account) FieldProof(name string) (*FieldProof, error) {
+	if a.version == AccountVersionLegacy {
+		return nil, ErrAccountVersion
+	}
+
+	if !a.IsSet(name) {
+		return nil, ErrFieldNotFound
+	}
+
+	leaves, keys := a.fieldLeaves()
+	keyH := Sha256H([]byte(name))
+	index := 0
+	for i, k := range keys {
+		if k.Equal(keyH) {
+			index = i
+			break
+		}
+	}
+
+	return &FieldProof{
+		Header: a.headerHash(),
+		Index:  index,
+		Count:  len(leaves),
+		Path:   MerkleProof(leaves, index),
+	}, nil
+}
+
+// VerifyFieldProof returns true, if field name=value is committed by
+// account hash root (check root with account signature)
+func VerifyFieldProof(root Hash256, name, value string, proof *FieldProof) bool {
+	if proof == nil {
+		return false
+	}
+
+	leaf := fieldLeaf(Sha256H([]byte(name)), value)
+	fieldsRoot, ok := MerkleProofRoot(leaf, proof.Index, proof.Count, proof.Path)
+	if !ok {
+		return false
+	}
+
+	return accountRoot(proof.Header, fieldsRoot, proof.Count).Equal(root)
+}
+
+// Verify account signature, legacy (version 0) accounts are rejected,
+// because their hash doesn't cover all account data
 func (a *Account) Verify() bool {
+	return a.version != AccountVersionLegacy && a.VerifyLegacy()
+}
+
+// VerifyLegacy is like Verify, but accepts legacy accounts too. Name,
+// timestamp and all fields but one of legacy account can be changed
+// without breaking the signature.
+func (a *Account) VerifyLegacy() bool {
 	hash := a.GetHash()
 	return Verify(&a.pub, hash[:], &a.sign)
 }
 
-// Bytes returns binary account data
+// Account encoding limits
+const (
+	AccountHeaderLen   = 1 + PubKeyLen + 4 + SignSize
+	accountChainLen    = 8 + 32
+	AccountMaxNameLen  = 1024
+	AccountMaxFields   = 256
+	AccountMaxKeyLen   = 256
+	AccountMaxValueLen = 64 * 1024
+)
+
+// Account encoding errors
+var (
+	ErrAccountData         = errors.New("invalid account data")
+	ErrAccountLimit        = errors.New("account exceeds encoding limits")
+	ErrAccountNotCanonical = errors.New("account encoding is not canonical")
+	ErrAccountTrailingData = errors.New("trailing data after account")
+)
+
+// Validate checks account against encoding limits
+func (a *Account) Validate() error {
+	if len(a.name) > AccountMaxNameLen {
+		return fmt.Errorf("%w: name length %d > %d", ErrAccountLimit, len(a.name), AccountMaxNameLen)
+	}
+
+	if len(a.fields) > AccountMaxFields {
+		return fmt.Errorf("%w: %d fields > %d", ErrAccountLimit, len(a.fields), AccountMaxFields)
+	}
+
+	for k, v := range a.fields {
+		if len(k) > AccountMaxKeyLen {
+			return fmt.Errorf("%w: field %q key length %d > %d", ErrAccountLimit, k, len(k), AccountMaxKeyLen)
+		}
+
+		if len(v) > AccountMaxValueLen {
+			return fmt.Errorf("%w: field %q value length %d > %d", ErrAccountLimit, k, len(v), AccountMaxValueLen)
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys returns field names in ascending byte order
+func (a *Account) sortedKeys() []string {
+	keys := make([]string, 0, len(a.fields))
+	for k := range a.fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Bytes returns canonical binary account data:
+//
+//	version (1) | pub (32) | timestamp (4, LE) | signature (64) |
+//	[sequence (8, LE) | prev hash (32), since version 2] |
+//	uvarint len | name | uvarint field count |
+//	(uvarint len | key | uvarint len | value)...
+//
+// fields are sorted by key, so equal accounts always have equal encoding.
+// Accounts over limits (see Validate) are encoded, but SetBytes rejects them.
 func (a *Account) Bytes() []byte {
-	data := make([]byte, 100)
-	copy(data[:32], a.pub[:])
-	PutUint32Le(data[32:36], a.timestamp)
-	copy(data[36:], a.sign[:])
+	data := make([]byte, AccountHeaderLen)
+	data[0] = a.version
+	copy(data[1:33], a.pub[:])
+	PutUint32Le(data[33:37], a.timestamp)
+	copy(data[37:], a.sign[:])
 
 	buf := bytes.NewBuffer(data)
-	buf.WriteByte(byte(len(a.name)))
-	buf.Write([]byte(a.name))
+	if a.version >= AccountVersion2 {
+		seq := make([]byte, 8)
+		PutUint64Le(seq, a.seq)
+		buf.Write(seq)
+		buf.Write(a.prev[:])
+	}
 
-	for k, v := range a.fields {
-		buf.WriteByte(byte(len(k)))
-		buf.Write([]byte(k))
-		buf.WriteByte(byte(len(v)))
-		buf.Write([]byte(v))
+	writeUvarintString(buf, a.name)
+	writeUvarint(buf, uint64(len(a.fields)))
+	for _, k := range a.sortedKeys() {
+		writeUvarintString(buf, k)
+		writeUvarintString(buf, a.fields[k])
 	}
 
 	return buf.Bytes()
 }
 
-// SetBytes decode raw account
+// SetBytes decode raw account, the input must be canonical
+// (as returned by Bytes) and within encoding limits
 func (a *Account) SetBytes(b []byte) (*Account, error) {
-	if len(b) < 100 {
-		return nil, fmt.Errorf("invalid data size")
+	if len(b) < AccountHeaderLen {
+		return nil, fmt.Errorf("%w: size %d < %d", ErrAccountData, len(b), AccountHeaderLen)
 	}
 
 	var (
 		pub PubKey
 		sig SigData
-		err error
-		sz  byte
 	)
 
-	copy(pub[:], b[:32])
-	ts := Uint32Le(b[32:36])
-	copy(sig[:], b[36:100])
+	ver := b[0]
+	if ver > AccountVersionLatest {
+		return nil, fmt.Errorf("%w: %d", ErrAccountVersion, ver)
+	}
+
+	var (
+		seq  uint64
+		prev Hash256
+		body = b[AccountHeaderLen:]
+	)
 
-	r := bytes.NewReader(b[100:])
-	sz, err = r.ReadByte()
+	if ver >= AccountVersion2 {
+		if len(body) < accountChainLen {
+			return nil, fmt.Errorf("%w: size %d < %d", ErrAccountData, len(b), AccountHeaderLen+accountChainLen)
+		}
+
+		seq = Uint64Le(body[:8])
+		copy(prev[:], body[8:accountChainLen])
+		body = body[accountChainLen:]
+	}
+
+	copy(pub[:], b[1:33])
+	ts := Uint32Le(b[33:37])
+	copy(sig[:], b[37:AccountHeaderLen])
+
+	r := bytes.NewReader(body)
+	name, err := readUvarintString(r, AccountMaxNameLen, "name")
 	if err != nil {
 		return nil, err
 	}
 
-	data := make([]byte, sz)
-	if _, err = io.ReadFull(r, data); err != nil {
+	count, err := readUvarint(r, AccountMaxFields, "field count")
+	if err != nil {
 		return nil, err
 	}
 
-	name := string(data)
-	fields := make(map[string]string)
-	for err == nil {
-		sz, err = r.ReadByte()
+	fields := make(map[string]string, count)
+	prevKey := ""
+	for i := 0; i < count; i++ {
+		k, err := readUvarintString(r, AccountMaxKeyLen, "field key")
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		data := make([]byte, sz)
-		if _, err = io.ReadFull(r, data); err != nil {
-			continue
+		if i > 0 && k <= prevKey {
+			return nil, fmt.Errorf("%w: field %q is out of order", ErrAccountNotCanonical, k)
 		}
 
-		k := string(data)
-		sz, err = r.ReadByte()
+		v, err := readUvarintString(r, AccountMaxValueLen, "field value")
 		if err != nil {
-			continue
+			return nil, err
 		}
 
-		data = make([]byte, sz)
-		if _, err = io.ReadFull(r, data); err != nil {
-			continue
-		}
+		fields[k] = v
+		prevKey = k
+	}
 
-		fields[k] = string(data)
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrAccountTrailingData, r.Len())
 	}
 
+	a.version = ver
+	a.seq = seq
+	a.prev = prev
 	a.pub = pub
 	a.sign = sig
 	a.timestamp = ts
@@ -169,19 +408,75 @@ func (a *Account) SetBytes(b []byte) (*Account, error) {
 	return a, nil
 }
 
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
+
+func writeUvarintString(buf *bytes.Buffer, s string) {
+	writeUvarint(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// readUvarint reads minimally encoded uvarint not greater than limit
+func readUvarint(r *bytes.Reader, limit int, what string) (int, error) {
+	off := r.Size() - int64(r.Len())
+	v, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s at offset %d: %v", ErrAccountData, what, off, err)
+	}
+
+	if v > uint64(limit) {
+		return 0, fmt.Errorf("%w: %s %d > %d", ErrAccountLimit, what, v, limit)
+	}
+
+	var tmp [binary.MaxVarintLen64]byte
+	if int64(binary.PutUvarint(tmp[:], v)) != r.Size()-int64(r.Len())-off {
+		return 0, fmt.Errorf("%w: %s at offset %d", ErrAccountNotCanonical, what, off)
+	}
+
+	return int(v), nil
+}
+
+func readUvarintString(r *bytes.Reader, limit int, what string) (string, error) {
+	n, err := readUvarint(r, limit, what+" length")
+	if err != nil {
+		return "", err
+	}
+
+	if n > r.Len() {
+		return "", fmt.Errorf("%w: %s is truncated", ErrAccountData, what)
+	}
+
+	data := make([]byte, n)
+	io.ReadFull(r, data)
+	return string(data), nil
+}
+
 // ExportJSON returns JSON-encoded account
 func (a *Account) ExportJSON() ([]byte, error) {
+	var prev *Hash256
+	if !a.prev.Empty() {
+		prev = &a.prev
+	}
+
 	return json.Marshal(&struct {
-		PublicKey string            `json:"public_key"`
+		Version   uint8             `json:"version,omitempty"`
+		PublicKey PubKey            `json:"public_key"`
 		Name      string            `json:"name"`
 		Timestamp uint32            `json:"timestamp"`
 		Fields    map[string]string `json:"fields"`
-		Signature string            `json:"signature"`
+		Sequence  uint64            `json:"sequence,omitempty"`
+		Prev      *Hash256          `json:"prev,omitempty"`
+		Signature SigData           `json:"signature"`
 	}{
+		Version:   a.version,
+		Sequence:  a.seq,
+		Prev:      prev,
 		Fields:    a.fields,
 		Name:      a.name,
-		PublicKey: a.pub.String(),
-		Signature: a.sign.String(),
+		PublicKey: a.pub,
+		Signature: a.sign,
 		Timestamp: a.timestamp,
 	})
 }
@@ -189,10 +484,13 @@ func (a *Account) ExportJSON() ([]byte, error) {
 // ImportJSON decodes account from JSON
 func (a *Account) ImportJSON(data []byte) (*Account, error) {
 	var tmp struct {
+		Version   uint8             `json:"version"`
 		PublicKey string            `json:"public_key"`
 		Name      string            `json:"name"`
 		Timestamp uint32            `json:"timestamp"`
 		Fields    map[string]string `json:"fields"`
+		Sequence  uint64            `json:"sequence"`
+		Prev      string            `json:"prev"`
 		Signature string            `json:"signature"`
 	}
 
@@ -200,10 +498,34 @@ func (a *Account) ImportJSON(data []byte) (*Account, error) {
 		return nil, err
 	}
 
-	a.pub = *(new(PubKey).SetString(tmp.PublicKey))
+	if tmp.Version > AccountVersionLatest {
+		return nil, fmt.Errorf("%w: %d", ErrAccountVersion, tmp.Version)
+	}
+
+	pub, err := ParsePubKey(tmp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: public_key: %v", ErrAccountData, err)
+	}
+
+	sig, err := ParseSigData(tmp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrAccountData, err)
+	}
+
+	var prev Hash256
+	if tmp.Prev != "" {
+		if prev, err = ParseHash256(tmp.Prev); err != nil {
+			return nil, fmt.Errorf("%w: prev: %v", ErrAccountData, err)
+		}
+	}
+
+	a.version = tmp.Version
+	a.seq = tmp.Sequence
+	a.prev = prev
+	a.pub = *pub
 	a.name = tmp.Name
 	a.fields = tmp.Fields
-	a.sign = *(new(SigData).SetString(tmp.Signature))
+	a.sign = *sig
 	a.timestamp = tmp.Timestamp
 	return a, nil
 }
@@ -234,6 +556,11 @@ func (k *Keypair) PublicKey() *PubKey {
 	return pub
 }
 
+// Format implements fmt.Formatter, only public key is printed
+func (k Keypair) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, "Keypair(%s)", &k.pub)
+}
+
 // Sign data
 func (k *Keypair) Sign(data []byte) *SigData {
 	return Sign(&k.priv, data)
@@ -242,11 +569,39 @@ func (k *Keypair) Sign(data []byte) *SigData {
 // GetAccount create account with keypair's public key
 func (k *Keypair) GetAccount(name string, fields map[string]string) *Account {
 	a := &Account{
+		version:   AccountVersionLatest,
 		fields:    fields,
 		name:      name,
 		timestamp: uint32(time.Now().Unix()),
 	}
 
+	return k.signAccount(a)
+}
+
+// UpdateAccount creates next version of prev account, it's linked to prev
+// by sequence number and hash
+func (k *Keypair) UpdateAccount(prev *Account, name string, fields map[string]string) (*Account, error) {
+	if !prev.pub.Equal(&k.pub) {
+		return nil, ErrAccountKey
+	}
+
+	a := &Account{
+		version:   AccountVersionLatest,
+		fields:    fields,
+		name:      name,
+		timestamp: uint32(time.Now().Unix()),
+		seq:       prev.seq + 1,
+		prev:      prev.GetHash(),
+	}
+
+	if a.timestamp < prev.timestamp {
+		a.timestamp = prev.timestamp
+	}
+
+	return k.signAccount(a), nil
+}
+
+func (k *Keypair) signAccount(a *Account) *Account {
 	copy(a.pub[:], k.pub[:])
 	hash := a.GetHash()
 	a.sign = *(k.Sign(hash[:]))
@@ -284,47 +639,21 @@ func (k *Keypair) SetBytes(b []byte) (*Keypair, error) {
 	return k, nil
 }
 
-// GetEncrypted cipher raw keypair
+// GetEncrypted cipher raw keypair with password-derived key
+// (Argon2id with DefaultKDFParams)
 func (k *Keypair) GetEncrypted(passw string) ([]byte, error) {
-	key := Sha256H(Sha256H([]byte(passw)).Bytes())
-	block, err := aes.NewCipher(key.Bytes())
-	if err != nil {
-		return nil, err
-	}
-
-	nonce := make([]byte, 12)
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
+	return k.GetEncryptedWith(passw, DefaultKDFParams)
+}
 
-	ciphertext := gcm.Seal(nil, nonce, k.Bytes(), nil)
-	return append(nonce, ciphertext...), nil
+// GetEncryptedWith cipher raw keypair with given KDF parameters
+func (k *Keypair) GetEncryptedWith(passw string, params KDFParams) ([]byte, error) {
+	return sealKeyEnvelope(k.Bytes(), passw, params)
 }
 
-// SetEncrypted decrypt ciphered keypair and deserialize it
+// SetEncrypted decrypt ciphered keypair and deserialize it,
+// legacy (unversioned) envelopes are supported too
 func (k *Keypair) SetEncrypted(input []byte, passw string) (*Keypair, error) {
-	if len(input) < 12 {
-		return nil, fmt.Errorf("invalid ciphertext")
-	}
-
-	key := Sha256H(Sha256H([]byte(passw)).Bytes())
-	block, err := aes.NewCipher(key.Bytes())
-	if err != nil {
-		return nil, err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-
-	// 0-12 byte is nonce, 12-... is ciphertext
-	data, err := gcm.Open(nil, input[:12], input[12:], nil)
+	data, err := openKeyEnvelope(input, passw)
 	if err != nil {
 		return nil, err
 	}
@@ -358,6 +687,24 @@ func (a *MyAccount) GetAccount() *Account {
 	return a.Keys.GetAccount(a.Name, a.Fields)
 }
 
+// UpdateAccount returns next version of prev public account
+func (a *MyAccount) UpdateAccount(prev *Account) (*Account, error) {
+	return a.Keys.UpdateAccount(prev, a.Name, a.Fields)
+}
+
+// Rotate generates new keypair, replaces account keys with it and returns
+// rotation from the old keys to the new ones
+func (a *MyAccount) Rotate() (*Rotation, error) {
+	keys, err := NewKeypair()
+	if err != nil {
+		return nil, err
+	}
+
+	rot := a.Keys.RotateTo(keys)
+	a.Keys = keys
+	return rot, nil
+}
+
 // ExportJSON encodes account to JSON with keys encryption
 func (a *MyAccount) ExportJSON(passw string) ([]byte, error) {
 	enc, err := a.Keys.GetEncrypted(passw)
@@ -388,7 +735,12 @@ func (a *MyAccount) ImportJSON(data []byte, passw string) (*MyAccount, error) {
 		return nil, err
 	}
 
-	keys, err := new(Keypair).SetEncrypted(HexDec(tmp.Keys), passw)
+	enc, err := HexDecodeStrict(tmp.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("keys: %w", err)
+	}
+
+	keys, err := new(Keypair).SetEncrypted(enc, passw)
 	if err != nil {
 		return nil, err
 	}
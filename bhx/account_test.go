@@ -1,6 +1,11 @@
 package bhx
 
-import "testing"
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
 
 func TestAccounts(t *testing.T) {
 	acc1, err := MakeNewAccount("tester1")
@@ -44,3 +49,244 @@ func TestAccounts(t *testing.T) {
 
 	Logf("-- decode ok, %+v", acc3)
 }
+
+func legacyEncrypt(t *testing.T, k *Keypair, passw string) []byte {
+	key := Sha256H(Sha256H([]byte(passw)).Bytes())
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := make([]byte, 12)
+	rand.Read(nonce)
+	return gcm.Seal(nonce, nonce, k.Bytes(), nil)
+}
+
+func TestKeypairEncryption(t *testing.T) {
+	keys, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := KDFParams{Time: 1, Memory: 1024, Threads: 1}
+	enc, err := keys.GetEncryptedWith("testpass", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, err := KeyEncVersion(enc); err != nil || ver != KeyEncVersion1 {
+		t.Fatalf("unexpected envelope version %d (%v)", ver, err)
+	}
+
+	dec, err := new(Keypair).SetEncrypted(enc, "testpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(dec.Bytes(), keys.Bytes()) {
+		t.Fatal("decrypted keypair mismatch")
+	}
+
+	if _, err := new(Keypair).SetEncrypted(enc, "wrongpass"); err == nil {
+		t.Fatal("decrypted with wrong password")
+	}
+
+	// header is authenticated: the same key doesn't open the ciphertext
+	// without header as additional data
+	gcm, err := newGCM(params.deriveKey("testpass", enc[15:keyEncHeaderLen]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce, ct := enc[keyEncHeaderLen:keyEncHeaderLen+keyEncNonceLen], enc[keyEncHeaderLen+keyEncNonceLen:]
+	if _, err := gcm.Open(nil, nonce, ct, enc[:keyEncHeaderLen]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := gcm.Open(nil, nonce, ct, nil); err == nil {
+		t.Fatal("decrypted without header")
+	}
+
+	// memory parameter is checked before key derivation
+	huge := append([]byte{}, enc...)
+	PutUint32Le(huge[10:14], maxKDFMemory+1)
+	if _, err := new(Keypair).SetEncrypted(huge, "testpass"); !errors.Is(err, ErrInvalidKDFParams) {
+		t.Fatalf("want %v, got %v", ErrInvalidKDFParams, err)
+	}
+
+	legacy := legacyEncrypt(t, keys, "testpass")
+	dec, err = new(Keypair).SetEncrypted(legacy, "testpass")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(dec.Bytes(), keys.Bytes()) {
+		t.Fatal("decrypted legacy keypair mismatch")
+	}
+}
+
+func testAccount(t testing.TB) *Account {
+	acc, err := MakeNewAccount("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		acc.Fields[HexEnc([]byte{byte(i)})] = HexEnc(make([]byte, 100*i))
+	}
+
+	return acc.GetAccount()
+}
+
+func TestAccountBytes(t *testing.T) {
+	acc := testAccount(t)
+	raw := acc.Bytes()
+	for i := 0; i < 10; i++ {
+		if !bytes.Equal(raw, acc.Bytes()) {
+			t.Fatal("account encoding is not deterministic")
+		}
+	}
+
+	dec, err := new(Account).SetBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dec.Verify() || !bytes.Equal(raw, dec.Bytes()) {
+		t.Fatal("decoded account mismatch")
+	}
+
+	if _, err := new(Account).SetBytes(append(raw, 0)); !errors.Is(err, ErrAccountTrailingData) {
+		t.Fatalf("want %v, got %v", ErrAccountTrailingData, err)
+	}
+
+	if _, err := new(Account).SetBytes(raw[:len(raw)-1]); !errors.Is(err, ErrAccountData) {
+		t.Fatalf("want %v, got %v", ErrAccountData, err)
+	}
+
+	acc.name = string(make([]byte, AccountMaxNameLen+1))
+	if err := acc.Validate(); !errors.Is(err, ErrAccountLimit) {
+		t.Fatalf("want %v, got %v", ErrAccountLimit, err)
+	}
+
+	if _, err := new(Account).SetBytes(acc.Bytes()); !errors.Is(err, ErrAccountLimit) {
+		t.Fatalf("want %v, got %v", ErrAccountLimit, err)
+	}
+}
+
+func FuzzAccountBytes(f *testing.F) {
+	f.Add(testAccount(f).Bytes())
+	f.Add((&Account{}).Bytes())
+	f.Add((&Account{name: "a", fields: map[string]string{"": "", "b": "c"}}).Bytes())
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		acc, err := new(Account).SetBytes(data)
+		if err != nil {
+			return
+		}
+
+		// every accepted input is canonical
+		raw := acc.Bytes()
+		if !bytes.Equal(raw, data) {
+			t.Fatalf("round trip mismatch:\n%x\n%x", data, raw)
+		}
+
+		acc2, err := new(Account).SetBytes(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(acc2.Bytes(), raw) {
+			t.Fatal("second round trip mismatch")
+		}
+	})
+}
+
+func TestAccountHash(t *testing.T) {
+	acc := testAccount(t)
+	if acc.Version() != AccountVersionLatest || !acc.Verify() {
+		t.Fatal("verification failed")
+	}
+
+	// every field and header item is committed
+	root := acc.GetHash()
+	for k := range acc.fields {
+		acc.fields[k] += "x"
+		if acc.GetHash().Equal(root) || acc.Verify() {
+			t.Fatalf("field %q is not committed", k)
+		}
+		acc.fields[k] = acc.fields[k][:len(acc.fields[k])-1]
+	}
+
+	acc.name += "x"
+	if acc.Verify() {
+		t.Fatal("name is not committed")
+	}
+	acc.name = acc.name[:len(acc.name)-1]
+
+	acc.timestamp++
+	if acc.Verify() {
+		t.Fatal("timestamp is not committed")
+	}
+	acc.timestamp--
+
+	// legacy accounts are verified with legacy hash
+	keys, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := &Account{pub: keys.pub, name: "old", fields: map[string]string{"a": "1", "b": "2"}}
+	hash := legacy.GetHash()
+	legacy.sign = *keys.Sign(hash[:])
+
+	dec, err := new(Account).SetBytes(legacy.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dec.Version() != AccountVersionLegacy || dec.Verify() || !dec.VerifyLegacy() {
+		t.Fatal("legacy verification failed")
+	}
+
+	dec.name = "mutated"
+	if !dec.VerifyLegacy() {
+		t.Fatal("legacy hash should not cover name")
+	}
+
+	if _, err := dec.FieldProof("a"); !errors.Is(err, ErrAccountVersion) {
+		t.Fatalf("want %v, got %v", ErrAccountVersion, err)
+	}
+}
+
+func TestFieldProof(t *testing.T) {
+	acc := testAccount(t)
+	root := acc.GetHash()
+	if !Verify(&acc.pub, root[:], &acc.sign) {
+		t.Fatal("verification failed")
+	}
+
+	for k, v := range acc.fields {
+		proof, err := acc.FieldProof(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !VerifyFieldProof(root, k, v, proof) {
+			t.Fatalf("field %q proof verification failed", k)
+		}
+
+		if VerifyFieldProof(root, k, v+"x", proof) {
+			t.Fatalf("field %q proof accepts wrong value", k)
+		}
+
+		proof.Count++
+		if VerifyFieldProof(root, k, v, proof) {
+			t.Fatalf("field %q proof accepts wrong count", k)
+		}
+	}
+
+	if _, err := acc.FieldProof("missing"); !errors.Is(err, ErrFieldNotFound) {
+		t.Fatalf("want %v, got %v", ErrFieldNotFound, err)
+	}
+}
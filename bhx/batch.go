@@ -0,0 +1,153 @@
+package bhx
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"io"
+	"sort"
+
+	"filippo.io/edwards25519"
+)
+
+// Ed25519 batch verification checks random linear combination of
+// signature equations with single multiscalar multiplication:
+//
+//	[8]([-sum z_i s_i]B + sum [z_i]R_i + sum [z_i k_i]A_i) == 0
+//
+// If batch fails, it's split in halves to find invalid entries, which are
+// checked with Verify. Batch equation is cofactored, so accepted batch is
+// valid for cofactored verification. It agrees with Verify for signatures
+// made by ed25519 signers, but crafted signatures with small order
+// components of A or R may pass the batch and fail Verify.
+
+// batchMinLen is batch size, which is checked with Verify one by one
+const batchMinLen = 4
+
+// BatchEntry is signature to verify with VerifyBatch
+type BatchEntry struct {
+	Pub *PubKey
+	Msg []byte
+	Sig *SigData
+}
+
+type batchItem struct {
+	index int
+	entry *BatchEntry
+	a, r  *edwards25519.Point
+	s, k  *edwards25519.Scalar
+}
+
+// VerifyBatch verifies signatures and returns sorted indexes of invalid
+// entries, nil if all signatures are valid
+func VerifyBatch(entries []BatchEntry) (failed []int) {
+	items := make([]*batchItem, 0, len(entries))
+	for i := range entries {
+		it, ok := newBatchItem(i, &entries[i])
+		if !ok {
+			failed = append(failed, i)
+			continue
+		}
+
+		items = append(items, it)
+	}
+
+	failed = append(failed, verifyBatchItems(items)...)
+	sort.Ints(failed)
+	return failed
+}
+
+// VerifyAccounts verifies account signatures and returns indexes of
+// accounts with invalid signature, nil if all accounts are valid. Like
+// Account.Verify, it rejects legacy accounts.
+func VerifyAccounts(accounts []*Account) (failed []int) {
+	entries := make([]BatchEntry, 0, len(accounts))
+	index := make([]int, 0, len(accounts))
+	for i, a := range accounts {
+		if a.version == AccountVersionLegacy {
+			failed = append(failed, i)
+			continue
+		}
+
+		hash := a.GetHash()
+		entries = append(entries, BatchEntry{Pub: &a.pub, Msg: hash[:], Sig: &a.sign})
+		index = append(index, i)
+	}
+
+	for _, i := range VerifyBatch(entries) {
+		failed = append(failed, index[i])
+	}
+
+	sort.Ints(failed)
+	return failed
+}
+
+func newBatchItem(index int, e *BatchEntry) (*batchItem, bool) {
+	a, err := new(edwards25519.Point).SetBytes(e.Pub[:])
+	if err != nil {
+		return nil, false
+	}
+
+	// Verify compares encoded R, so R must be canonical
+	r, err := new(edwards25519.Point).SetBytes(e.Sig[:32])
+	if err != nil || string(r.Bytes()) != string(e.Sig[:32]) {
+		return nil, false
+	}
+
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(e.Sig[32:])
+	if err != nil {
+		return nil, false
+	}
+
+	h := sha512.New()
+	h.Write(e.Sig[:32])
+	h.Write(e.Pub[:])
+	h.Write(e.Msg)
+	k, _ := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	return &batchItem{index: index, entry: e, a: a, r: r, s: s, k: k}, true
+}
+
+// verifyBatchItems returns indexes of invalid items
+func verifyBatchItems(items []*batchItem) (failed []int) {
+	if len(items) < batchMinLen {
+		for _, it := range items {
+			if !Verify(it.entry.Pub, it.entry.Msg, it.entry.Sig) {
+				failed = append(failed, it.index)
+			}
+		}
+
+		return failed
+	}
+
+	if batchEquation(items) {
+		return nil
+	}
+
+	half := len(items) / 2
+	return append(verifyBatchItems(items[:half]), verifyBatchItems(items[half:])...)
+}
+
+func batchEquation(items []*batchItem) bool {
+	scalars := make([]*edwards25519.Scalar, 0, 1+2*len(items))
+	points := make([]*edwards25519.Point, 0, 1+2*len(items))
+	sumS := edwards25519.NewScalar()
+	scalars = append(scalars, sumS)
+	points = append(points, edwards25519.NewGeneratorPoint())
+
+	var buf [32]byte
+	for _, it := range items {
+		// 128-bit random coefficient
+		if _, err := io.ReadFull(rand.Reader, buf[:16]); err != nil {
+			return false
+		}
+
+		z, _ := edwards25519.NewScalar().SetCanonicalBytes(buf[:])
+		sumS.MultiplyAdd(z, it.s, sumS)
+		scalars = append(scalars, z, edwards25519.NewScalar().Multiply(z, it.k))
+		points = append(points, it.r, it.a)
+	}
+
+	sumS.Negate(sumS)
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+	check.MultByCofactor(check)
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
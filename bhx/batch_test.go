@@ -0,0 +1,171 @@
+package bhx
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+func batchEntries(t testing.TB, n int) []BatchEntry {
+	entries := make([]BatchEntry, n)
+	for i := range entries {
+		k, err := NewKeypair()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		msg := []byte(fmt.Sprintf("message %d", i))
+		entries[i] = BatchEntry{Pub: &k.pub, Msg: msg, Sig: k.Sign(msg)}
+	}
+
+	return entries
+}
+
+func TestVerifyBatch(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 16, 65} {
+		entries := batchEntries(t, n)
+		if failed := VerifyBatch(entries); failed != nil {
+			t.Fatalf("%d entries: valid signatures failed: %v", n, failed)
+		}
+
+		if n < 16 {
+			continue
+		}
+
+		for _, i := range []int{2, 7, n - 1} {
+			entries[i].Msg = []byte("forged")
+		}
+
+		// s >= L
+		sig := *entries[9].Sig
+		for i := 32; i < SignSize; i++ {
+			sig[i] = 0xff
+		}
+
+		entries[9].Sig = &sig
+		want := []int{2, 7, 9, n - 1}
+		failed := VerifyBatch(entries)
+		if !reflect.DeepEqual(failed, want) {
+			t.Fatalf("%d entries: want failed %v, got %v", n, want, failed)
+		}
+	}
+}
+
+// torsionEntry returns signature, which is valid for cofactored
+// equation only: public key has order 2 component and k is odd
+func torsionEntry(t testing.TB) BatchEntry {
+	k, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := sha512.Sum512(k.priv[:32])
+	a, _ := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	r, _ := edwards25519.NewScalar().SetUniformBytes(h[:]) // fixed nonce is fine here
+
+	// (0, -1) has order 2
+	t2 := make([]byte, 32)
+	for i := range t2 {
+		t2[i] = 0xff
+	}
+	t2[0], t2[31] = 0xec, 0x7f
+
+	torsion, err := new(edwards25519.Point).SetBytes(t2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pub PubKey
+	copy(pub[:], new(edwards25519.Point).Add(new(edwards25519.Point).ScalarBaseMult(a), torsion).Bytes())
+	R := new(edwards25519.Point).ScalarBaseMult(r).Bytes()
+	for i := 0; ; i++ {
+		msg := []byte(fmt.Sprintf("torsion %d", i))
+		d := sha512.New()
+		d.Write(R)
+		d.Write(pub[:])
+		d.Write(msg)
+		kk, _ := edwards25519.NewScalar().SetUniformBytes(d.Sum(nil))
+		if kk.Bytes()[0]&1 == 0 {
+			continue
+		}
+
+		var sig SigData
+		copy(sig[:32], R)
+		copy(sig[32:], edwards25519.NewScalar().MultiplyAdd(kk, a, r).Bytes())
+		return BatchEntry{Pub: &pub, Msg: msg, Sig: &sig}
+	}
+}
+
+func TestVerifyBatchTorsion(t *testing.T) {
+	entries := batchEntries(t, 16)
+	entries[5] = torsionEntry(t)
+	if Verify(entries[5].Pub, entries[5].Msg, entries[5].Sig) {
+		t.Fatal("torsion signature passed Verify")
+	}
+
+	// batch equation is cofactored
+	if failed := VerifyBatch(entries); failed != nil {
+		t.Fatalf("cofactored batch failed: %v", failed)
+	}
+
+	// short batch is checked with Verify
+	if failed := VerifyBatch(entries[4:7]); !reflect.DeepEqual(failed, []int{1}) {
+		t.Fatalf("want failed [1], got %v", failed)
+	}
+}
+
+func TestVerifyAccounts(t *testing.T) {
+	accounts := make([]*Account, 8)
+	for i := range accounts {
+		a, err := MakeNewAccount(fmt.Sprintf("user%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		accounts[i] = a.GetAccount()
+	}
+
+	if failed := VerifyAccounts(accounts); failed != nil {
+		t.Fatalf("valid accounts failed: %v", failed)
+	}
+
+	accounts[5].name = "mallory"
+	if failed := VerifyAccounts(accounts); !reflect.DeepEqual(failed, []int{5}) {
+		t.Fatalf("want failed [5], got %v", failed)
+	}
+
+	keys, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := &Account{pub: keys.pub, name: "old", fields: map[string]string{"a": "1"}}
+	hash := legacy.GetHash()
+	legacy.sign = *keys.Sign(hash[:])
+	accounts[2] = legacy
+	if failed := VerifyAccounts(accounts); !reflect.DeepEqual(failed, []int{2, 5}) {
+		t.Fatalf("want failed [2 5], got %v", failed)
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	for _, n := range []int{16, 64, 256} {
+		entries := batchEntries(b, n)
+		b.Run(fmt.Sprintf("batch-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyBatch(entries)
+			}
+		})
+
+		b.Run(fmt.Sprintf("loop-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, e := range entries {
+					Verify(e.Pub, e.Msg, e.Sig)
+				}
+			}
+		})
+	}
+}
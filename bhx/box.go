@@ -2,8 +2,11 @@ package bhx
 
 import (
 	"crypto/rand"
+	"crypto/sha512"
+	"errors"
 	"io"
 
+	"filippo.io/edwards25519"
 	"golang.org/x/crypto/nacl/box"
 )
 
@@ -16,6 +19,9 @@ const (
 	BoxOverhead     = box.Overhead
 )
 
+// ErrInvalidPubKey is returned for public key, which isn't valid curve point
+var ErrInvalidPubKey = errors.New("invalid public key")
+
 // BoxPub is NaCl box public key
 type BoxPub [BoxPubKeyLen]byte
 
@@ -89,3 +95,44 @@ func BoxOpen(msg []byte, key *BoxSharedKey) ([]byte, bool) {
 	copy(k[:], key[:])
 	return box.OpenAfterPrecomputation(nil, msg[24:], &n, &k)
 }
+
+// BoxPrivOf converts ed25519 private key to NaCl box (X25519) private key
+func BoxPrivOf(priv *PrivKey) *BoxPriv {
+	var bPriv BoxPriv
+	h := sha512.Sum512(SeedOf(priv))
+	copy(bPriv[:], h[:BoxPrivKeyLen])
+	bPriv[0] &= 248
+	bPriv[31] &= 127
+	bPriv[31] |= 64
+	return &bPriv
+}
+
+// BoxPubOf converts ed25519 public key to NaCl box (X25519) public key
+// with birational map from edwards25519 to curve25519
+func BoxPubOf(pub *PubKey) (*BoxPub, error) {
+	p, err := new(edwards25519.Point).SetBytes(pub[:])
+	if err != nil {
+		return nil, ErrInvalidPubKey
+	}
+
+	// small order points give predictable shared keys
+	if new(edwards25519.Point).MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, ErrInvalidPubKey
+	}
+
+	var bPub BoxPub
+	copy(bPub[:], p.BytesMontgomery())
+	return &bPub, nil
+}
+
+// BoxKeys returns NaCl box keys converted from the keypair
+func (k *Keypair) BoxKeys() (*BoxPub, *BoxPriv) {
+	// own public key is always valid
+	pub, _ := BoxPubOf(&k.pub)
+	return pub, BoxPrivOf(&k.priv)
+}
+
+// BoxPub returns NaCl box public key converted from account public key
+func (a *Account) BoxPub() (*BoxPub, error) {
+	return BoxPubOf(&a.pub)
+}
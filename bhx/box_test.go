@@ -0,0 +1,98 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestBoxKeysConversion(t *testing.T) {
+	alice, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob, err := MakeNewAccount("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aPub, aPriv := alice.BoxKeys()
+	pk, err := curve25519.X25519(aPriv[:], curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(pk, aPub[:]) {
+		t.Fatal("converted keys don't match")
+	}
+
+	// alice encrypts to bob's signed account without key exchange
+	bPub, err := bob.GetAccount().BoxPub()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("hello bob")
+	sealed := BoxSeal(GenerateNonce(), GetSharedKey(bPub, aPriv), msg)
+
+	_, bPriv := bob.Keys.BoxKeys()
+	opened, ok := BoxOpen(sealed, GetSharedKey(aPub, bPriv))
+	if !ok || !bytes.Equal(opened, msg) {
+		t.Fatal("cannot open box with converted keys")
+	}
+
+	// identity point has small order
+	var bad PubKey
+	bad[0] = 1
+	if _, err := BoxPubOf(&bad); !errors.Is(err, ErrInvalidPubKey) {
+		t.Fatalf("want %v, got %v", ErrInvalidPubKey, err)
+	}
+}
+
+func TestSealed(t *testing.T) {
+	// crypto_box_seal output from libsodium
+	var priv BoxPriv
+	copy(priv[:], HexDec("0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20"))
+	ct := HexDec("128a3585c03e817ebafea05e8a251ebb37d19839e4a268c0b60b948c5175533e" +
+		"ae9971d91f2f710c589298964e4987664e0015077afac6240d61a6528d91")
+
+	msg, err := OpenSealed(&priv, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(msg) != "sealed for bhx" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+
+	bob, err := MakeNewAccount("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct, err = SealToAccount(bob.GetAccount(), []byte("inbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ct) != len("inbox")+SealOverhead {
+		t.Fatalf("sealed size %d", len(ct))
+	}
+
+	_, bPriv := bob.Keys.BoxKeys()
+	if msg, err = OpenSealed(bPriv, ct); err != nil || string(msg) != "inbox" {
+		t.Fatalf("cannot open sealed box: %v", err)
+	}
+
+	ct[len(ct)-1] ^= 1
+	if _, err := OpenSealed(bPriv, ct); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("want %v, got %v", ErrDecrypt, err)
+	}
+
+	if _, err := OpenSealed(bPriv, ct[:SealOverhead-1]); !errors.Is(err, ErrInvalidByteLen) {
+		t.Fatalf("want %v, got %v", ErrInvalidByteLen, err)
+	}
+}
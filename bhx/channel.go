@@ -0,0 +1,322 @@
+package bhx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/flynn/noise"
+)
+
+// Secure channel over net.Conn with Noise_XX or Noise_IK handshake
+// (25519, ChaChaPoly, BLAKE2b). Noise static key is box key converted from
+// ed25519 keypair, every handshake payload with identity is
+//
+//	ed25519 pub (32) | signature of "bhx-channel" || static key (64)
+//
+// so peers are authenticated by PubKey. Handshake and transport messages
+// are framed with 2-byte LE length. Transport nonces are implicit counters,
+// so replayed, reordered or dropped frames break the channel.
+
+// ChannelPattern is Noise handshake pattern
+type ChannelPattern uint8
+
+// Channel patterns
+const (
+	ChannelXX ChannelPattern = iota // both static keys are exchanged
+	ChannelIK                       // initiator knows responder key, 1-RTT
+)
+
+// Channel constants
+const (
+	ChannelMaxFrame    = 1<<16 - 1
+	ChannelMaxPayload  = ChannelMaxFrame - 16
+	channelIdentityLen = PubKeyLen + SignSize
+)
+
+var (
+	channelPrologue   = []byte("bhx-channel-v1")
+	channelSignPrefix = []byte("bhx-channel")
+	channelSuite      = noise.NewCipherSuite(noise.DH25519, noise.CipherChaChaPoly, noise.HashBLAKE2b)
+)
+
+// Channel errors
+var (
+	ErrChannelHandshake = errors.New("channel handshake failed")
+	ErrChannelPeer      = errors.New("unexpected channel peer")
+	ErrChannelAuth      = errors.New("channel message authentication failed")
+)
+
+// ChannelConfig is secure channel settings
+type ChannelConfig struct {
+	Keys    *Keypair
+	Pattern ChannelPattern
+
+	// PeerKey is expected peer key (e.g. Account.PublicKey()), it's
+	// required for ChannelIK initiator
+	PeerKey *PubKey
+
+	// Verify is called with authenticated peer key, if set
+	Verify func(peer *PubKey) error
+}
+
+// Channel is encrypted and authenticated net.Conn. Read and Write
+// errors, including deadline errors, are permanent.
+type Channel struct {
+	conn net.Conn
+	peer PubKey
+
+	rmu  sync.Mutex
+	dec  *noise.CipherState
+	rbuf []byte
+	rerr error
+
+	wmu  sync.Mutex
+	enc  *noise.CipherState
+	werr error
+}
+
+// ChannelClient performs initiator handshake over conn
+func ChannelClient(conn net.Conn, cfg *ChannelConfig) (*Channel, error) {
+	return channelHandshake(conn, cfg, true)
+}
+
+// ChannelServer performs responder handshake over conn
+func ChannelServer(conn net.Conn, cfg *ChannelConfig) (*Channel, error) {
+	return channelHandshake(conn, cfg, false)
+}
+
+func channelHandshake(conn net.Conn, cfg *ChannelConfig, initiator bool) (*Channel, error) {
+	pub, priv := cfg.Keys.BoxKeys()
+	nc := noise.Config{
+		CipherSuite:   channelSuite,
+		Pattern:       noise.HandshakeXX,
+		Initiator:     initiator,
+		Prologue:      channelPrologue,
+		StaticKeypair: noise.DHKey{Private: priv[:], Public: pub[:]},
+	}
+
+	if cfg.Pattern == ChannelIK {
+		nc.Pattern = noise.HandshakeIK
+		if initiator {
+			if cfg.PeerKey == nil {
+				return nil, fmt.Errorf("%w: IK initiator needs peer key", ErrChannelHandshake)
+			}
+
+			peerStatic, err := BoxPubOf(cfg.PeerKey)
+			if err != nil {
+				return nil, err
+			}
+
+			nc.PeerStatic = peerStatic[:]
+		}
+	}
+
+	hs, err := noise.NewHandshakeState(nc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrChannelHandshake, err)
+	}
+
+	identity := channelIdentity(cfg.Keys, pub)
+	c := &Channel{conn: conn}
+	var peer *PubKey
+	for i := 0; c.enc == nil; i++ {
+		// first XX message has no encryption key yet
+		payload := identity
+		if i == 0 && cfg.Pattern == ChannelXX {
+			payload = nil
+		}
+
+		var cs1, cs2 *noise.CipherState
+		if (i%2 == 0) == initiator {
+			var msg []byte
+			if msg, cs1, cs2, err = hs.WriteMessage(nil, payload); err == nil {
+				err = writeChannelFrame(conn, msg)
+			}
+		} else {
+			var msg, got []byte
+			if msg, err = readChannelFrame(conn); err == nil {
+				got, cs1, cs2, err = hs.ReadMessage(nil, msg)
+			}
+
+			if err == nil && len(got) != len(payload) {
+				err = errors.New("unexpected payload")
+			}
+
+			if err == nil && len(got) > 0 {
+				peer, err = checkChannelIdentity(got, hs.PeerStatic())
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrChannelHandshake, err)
+		}
+
+		if cs1 != nil {
+			c.enc, c.dec = cs1, cs2
+			if !initiator {
+				c.enc, c.dec = cs2, cs1
+			}
+		}
+	}
+
+	if peer == nil {
+		return nil, fmt.Errorf("%w: no peer identity", ErrChannelHandshake)
+	}
+
+	if cfg.PeerKey != nil && !cfg.PeerKey.Equal(peer) {
+		return nil, fmt.Errorf("%w: %s", ErrChannelPeer, peer)
+	}
+
+	if cfg.Verify != nil {
+		if err := cfg.Verify(peer); err != nil {
+			return nil, err
+		}
+	}
+
+	c.peer = *peer
+	return c, nil
+}
+
+func channelIdentity(k *Keypair, static *BoxPub) []byte {
+	sig := k.Sign(append(append([]byte{}, channelSignPrefix...), static[:]...))
+	return append(append(make([]byte, 0, channelIdentityLen), k.pub[:]...), sig[:]...)
+}
+
+// checkChannelIdentity returns peer key, if it owns static key
+func checkChannelIdentity(identity, static []byte) (*PubKey, error) {
+	if len(identity) != channelIdentityLen || len(static) != BoxPubKeyLen {
+		return nil, errors.New("invalid identity")
+	}
+
+	peer := new(PubKey)
+	copy(peer[:], identity[:PubKeyLen])
+	var sig SigData
+	copy(sig[:], identity[PubKeyLen:])
+
+	bPub, err := BoxPubOf(peer)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := append(append([]byte{}, channelSignPrefix...), static...)
+	if string(bPub[:]) != string(static) || !Verify(peer, msg, &sig) {
+		return nil, errors.New("identity does not match static key")
+	}
+
+	return peer, nil
+}
+
+func writeChannelFrame(w io.Writer, msg []byte) error {
+	frame := make([]byte, 2, 2+len(msg))
+	PutUint16Le(frame, uint16(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+func readChannelFrame(r io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+
+	msg := make([]byte, Uint16Le(size[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// PeerKey returns authenticated peer key
+func (c *Channel) PeerKey() *PubKey {
+	k := new(PubKey)
+	copy(k[:], c.peer[:])
+	return k
+}
+
+// Read reads decrypted data
+func (c *Channel) Read(b []byte) (int, error) {
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	for len(c.rbuf) == 0 {
+		if c.rerr != nil {
+			return 0, c.rerr
+		}
+
+		msg, err := readChannelFrame(c.conn)
+		if err != nil {
+			// frame may be partially read
+			c.rerr = err
+			return 0, err
+		}
+
+		if c.rbuf, err = c.dec.Decrypt(msg[:0], nil, msg); err != nil {
+			c.rerr = ErrChannelAuth
+			return 0, c.rerr
+		}
+	}
+
+	n := copy(b, c.rbuf)
+	c.rbuf = c.rbuf[n:]
+	return n, nil
+}
+
+// Write encrypts and writes data in frames up to ChannelMaxPayload bytes
+func (c *Channel) Write(b []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.werr != nil {
+		return 0, c.werr
+	}
+
+	n := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > ChannelMaxPayload {
+			chunk = chunk[:ChannelMaxPayload]
+		}
+
+		msg, err := c.enc.Encrypt(nil, nil, chunk)
+		if err == nil {
+			err = writeChannelFrame(c.conn, msg)
+		}
+
+		if err != nil {
+			// partially written frame can't be recovered
+			c.werr = err
+			return n, err
+		}
+
+		n += len(chunk)
+		b = b[len(chunk):]
+	}
+
+	return n, nil
+}
+
+// Close closes underlying connection
+func (c *Channel) Close() error { return c.conn.Close() }
+
+// LocalAddr returns local network address
+func (c *Channel) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+// RemoteAddr returns remote network address
+func (c *Channel) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetDeadline sets read and write deadlines
+func (c *Channel) SetDeadline(t time.Time) error { return c.conn.SetDeadline(t) }
+
+// SetReadDeadline sets read deadline
+func (c *Channel) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetWriteDeadline sets write deadline
+func (c *Channel) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
@@ -0,0 +1,161 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func channelPair(t *testing.T, client, server *ChannelConfig) (*Channel, *Channel, error, error) {
+	t.Helper()
+	cConn, sConn := net.Pipe()
+	t.Cleanup(func() {
+		cConn.Close()
+		sConn.Close()
+	})
+
+	done := make(chan struct{})
+	var (
+		srv    *Channel
+		srvErr error
+	)
+
+	go func() {
+		defer close(done)
+		if srv, srvErr = ChannelServer(sConn, server); srvErr != nil {
+			sConn.Close()
+		}
+	}()
+
+	cli, cliErr := ChannelClient(cConn, client)
+	if cliErr != nil {
+		cConn.Close()
+	}
+
+	<-done
+	return cli, srv, cliErr, srvErr
+}
+
+func TestChannel(t *testing.T) {
+	alice, _ := NewKeypair()
+	bob, _ := NewKeypair()
+
+	for _, p := range []ChannelPattern{ChannelXX, ChannelIK} {
+		cli, srv, cErr, sErr := channelPair(t,
+			&ChannelConfig{Keys: alice, Pattern: p, PeerKey: &bob.pub},
+			&ChannelConfig{Keys: bob, Pattern: p})
+		if cErr != nil || sErr != nil {
+			t.Fatalf("pattern %d: %v, %v", p, cErr, sErr)
+		}
+
+		if !cli.PeerKey().Equal(&bob.pub) || !srv.PeerKey().Equal(&alice.pub) {
+			t.Fatalf("pattern %d: unexpected peer keys", p)
+		}
+
+		// larger than single frame
+		msg := bytes.Repeat([]byte("bhx"), ChannelMaxPayload)
+		go func() {
+			cli.Write(msg)
+		}()
+
+		got := make([]byte, len(msg))
+		if _, err := io.ReadFull(srv, got); err != nil || !bytes.Equal(got, msg) {
+			t.Fatalf("pattern %d: unexpected data: %v", p, err)
+		}
+
+		go func() {
+			srv.Write([]byte("pong"))
+		}()
+
+		if _, err := io.ReadFull(cli, got[:4]); err != nil || string(got[:4]) != "pong" {
+			t.Fatalf("pattern %d: unexpected reply %q: %v", p, got[:4], err)
+		}
+	}
+}
+
+func TestChannelAuth(t *testing.T) {
+	alice, _ := NewKeypair()
+	bob, _ := NewKeypair()
+	eve, _ := NewKeypair()
+
+	for _, p := range []ChannelPattern{ChannelXX, ChannelIK} {
+		_, _, cErr, sErr := channelPair(t,
+			&ChannelConfig{Keys: alice, Pattern: p, PeerKey: &eve.pub},
+			&ChannelConfig{Keys: bob, Pattern: p})
+		if cErr == nil || (p == ChannelXX && !errors.Is(cErr, ErrChannelPeer)) {
+			t.Fatalf("pattern %d: wrong peer accepted: %v", p, cErr)
+		}
+
+		if p == ChannelIK && sErr == nil {
+			t.Fatal("responder accepted handshake for other key")
+		}
+	}
+
+	errDenied := errors.New("denied")
+	_, _, _, sErr := channelPair(t,
+		&ChannelConfig{Keys: alice},
+		&ChannelConfig{Keys: bob, Verify: func(peer *PubKey) error {
+			if peer.Equal(&alice.pub) {
+				return errDenied
+			}
+			return nil
+		}})
+	if !errors.Is(sErr, errDenied) {
+		t.Fatalf("want %v, got %v", errDenied, sErr)
+	}
+
+	if _, err := ChannelClient(nil, &ChannelConfig{Keys: alice, Pattern: ChannelIK}); !errors.Is(err, ErrChannelHandshake) {
+		t.Fatalf("want %v, got %v", ErrChannelHandshake, err)
+	}
+}
+
+func TestChannelReplay(t *testing.T) {
+	alice, _ := NewKeypair()
+	bob, _ := NewKeypair()
+
+	// record client frames
+	cConn, sConn := net.Pipe()
+	defer cConn.Close()
+	rec := &recordConn{Conn: cConn}
+
+	done := make(chan error)
+	var srv *Channel
+	go func() {
+		var err error
+		srv, err = ChannelServer(sConn, &ChannelConfig{Keys: bob})
+		done <- err
+	}()
+
+	cli, err := ChannelClient(rec, &ChannelConfig{Keys: alice})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	rec.buf.Reset()
+	go cli.Write([]byte("once"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(srv, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	go cConn.Write(rec.buf.Bytes())
+	if _, err := srv.Read(buf); !errors.Is(err, ErrChannelAuth) {
+		t.Fatalf("want %v, got %v", ErrChannelAuth, err)
+	}
+}
+
+type recordConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	c.buf.Write(b)
+	return c.Conn.Write(b)
+}
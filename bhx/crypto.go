@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"errors"
 
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/salsa20"
 )
 
@@ -12,9 +13,11 @@ var zeroNonce BoxNonce
 // Errors
 var (
 	ErrInvalidByteLen = errors.New("invalid byte length")
+	ErrDecrypt        = errors.New("message authentication failed")
 )
 
-// GetSha256Hash returns sha2-256 hash for given data
+// GetSha256Hash returns sha2-256 hash for given data, unlike Sha256H,
+// which is SHA3-256
 func GetSha256Hash(input ...[]byte) (h Hash256) {
 	sha := sha256.New()
 	for _, data := range input {
@@ -34,6 +37,8 @@ func GetKeyNonce(key BoxSharedKey) (nonce BoxNonce) {
 
 // Encrypt given data with salsa20 encryption
 // nnonce - nonce for decrypt nonce
+//
+// Deprecated: ciphertext is not authenticated, use EncryptAEAD
 func Encrypt(data []byte, key BoxSharedKey, nnonce BoxNonce) ([]byte, error) {
 	if len(data) == 0 {
 		return nil, ErrInvalidByteLen
@@ -53,6 +58,8 @@ func Encrypt(data []byte, key BoxSharedKey, nnonce BoxNonce) ([]byte, error) {
 
 // Decrypt given ciphertext with salsa20
 // nnonce - nonce for decrypt nonce
+//
+// Deprecated: ciphertext is not authenticated, use DecryptAEAD
 func Decrypt(ciphertext []byte, key BoxSharedKey, nnonce BoxNonce) ([]byte, error) {
 	if len(ciphertext) < BoxNonceLen+1 {
 		return nil, ErrInvalidByteLen
@@ -69,3 +76,37 @@ func Decrypt(ciphertext []byte, key BoxSharedKey, nnonce BoxNonce) ([]byte, erro
 	salsa20.XORKeyStream(result[:], ciphertext[BoxNonceLen:], nonce[:], (*[32]byte)(&key))
 	return result, nil
 }
+
+// EncryptAEAD encrypt and authenticate given data with XChaCha20-Poly1305,
+// ad is optional additional data, which is authenticated but not encrypted.
+// Result is nonce || ciphertext
+func EncryptAEAD(data []byte, key BoxSharedKey, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := GenerateNonce()
+	result := make([]byte, BoxNonceLen, BoxNonceLen+len(data)+aead.Overhead())
+	copy(result, nonce[:])
+	return aead.Seal(result, nonce[:], data, ad), nil
+}
+
+// DecryptAEAD decrypt ciphertext created by EncryptAEAD
+func DecryptAEAD(ciphertext []byte, key BoxSharedKey, ad []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < BoxNonceLen+aead.Overhead() {
+		return nil, ErrInvalidByteLen
+	}
+
+	result, err := aead.Open(nil, ciphertext[:BoxNonceLen], ciphertext[BoxNonceLen:], ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return result, nil
+}
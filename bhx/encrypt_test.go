@@ -2,6 +2,8 @@ package bhx
 
 import (
 	"bytes"
+	"crypto/rand"
+	"io"
 	"testing"
 )
 
@@ -26,3 +28,125 @@ func TestSalsaEncryption(t *testing.T) {
 		t.Fatalf("cannot decrypt data, want %s got %s", input, output)
 	}
 }
+
+func TestAEADEncryption(t *testing.T) {
+	var key BoxSharedKey
+	copy(key[:], Sha256H([]byte("1337")).Bytes())
+
+	input := []byte("Some text blablabla 123213213")
+	ad := []byte("header")
+	ciphertext, err := EncryptAEAD(input, key, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := DecryptAEAD(ciphertext, key, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(input, output) {
+		t.Fatalf("cannot decrypt data, want %s got %s", input, output)
+	}
+
+	if _, err := DecryptAEAD(ciphertext, key, nil); err != ErrDecrypt {
+		t.Fatalf("want %v, got %v", ErrDecrypt, err)
+	}
+
+	ciphertext[BoxNonceLen] ^= 1
+	if _, err := DecryptAEAD(ciphertext, key, ad); err != ErrDecrypt {
+		t.Fatalf("want %v, got %v", ErrDecrypt, err)
+	}
+}
+
+func encryptStream(t *testing.T, key BoxSharedKey, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// odd-sized writes to cross chunk boundaries
+	for len(data) > 0 {
+		n := 1000 + len(data)%7
+		if n > len(data) {
+			n = len(data)
+		}
+
+		if _, err := w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func decryptStream(key BoxSharedKey, data []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(data), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func TestStreamEncryption(t *testing.T) {
+	var key BoxSharedKey
+	copy(key[:], Sha256H([]byte("1337")).Bytes())
+
+	for _, size := range []int{0, 1, StreamChunkSize - 1, StreamChunkSize, 3*StreamChunkSize + 17} {
+		input := make([]byte, size)
+		rand.Read(input)
+
+		stream := encryptStream(t, key, input)
+		output, err := decryptStream(key, stream)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+
+		if !bytes.Equal(input, output) {
+			t.Fatalf("size %d: stream decryption mismatch", size)
+		}
+	}
+}
+
+func TestStreamTampering(t *testing.T) {
+	var key BoxSharedKey
+	copy(key[:], Sha256H([]byte("1337")).Bytes())
+
+	input := make([]byte, 3*StreamChunkSize+17)
+	rand.Read(input)
+	stream := encryptStream(t, key, input)
+	chunk := StreamChunkSize + StreamOverhead
+
+	tampered := append([]byte{}, stream...)
+	tampered[streamHeaderLen+chunk+5] ^= 1
+	if _, err := decryptStream(key, tampered); err != ErrDecrypt {
+		t.Fatalf("modified chunk: want %v, got %v", ErrDecrypt, err)
+	}
+
+	reordered := append([]byte{}, stream[:streamHeaderLen]...)
+	reordered = append(reordered, stream[streamHeaderLen+chunk:streamHeaderLen+2*chunk]...)
+	reordered = append(reordered, stream[streamHeaderLen:streamHeaderLen+chunk]...)
+	reordered = append(reordered, stream[streamHeaderLen+2*chunk:]...)
+	if _, err := decryptStream(key, reordered); err != ErrDecrypt {
+		t.Fatalf("reordered chunks: want %v, got %v", ErrDecrypt, err)
+	}
+
+	if _, err := decryptStream(key, stream[:streamHeaderLen+2*chunk]); err != ErrStreamTruncated {
+		t.Fatalf("truncated at chunk boundary: want %v, got %v", ErrStreamTruncated, err)
+	}
+
+	if _, err := decryptStream(key, stream[:len(stream)-3]); err != ErrDecrypt {
+		t.Fatalf("truncated final chunk: want %v, got %v", ErrDecrypt, err)
+	}
+
+	if _, err := decryptStream(key, append(stream, 0)); err == nil {
+		t.Fatal("trailing data accepted")
+	}
+}
@@ -0,0 +1,298 @@
+package bhx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// Envelope encrypts payload once with random content key, the key is
+// wrapped for every recipient with shared key of ephemeral envelope key
+// and recipient box key. Format:
+//
+//	magic "bhxe" | version (1) | flags (1) | ephemeral pub (32) | count (2, LE) | slots | payload
+//
+// Slot is slot id (32) | wrapped content key (72). Slot id is recipient box
+// public key or, for hidden recipients, tag derived from the shared key, so
+// recipient finds own slot with single key exchange. Slots are sorted by id.
+// Payload is EncryptAEAD(sender pub (32) | signature (64) | msg) with the
+// header as additional data, sender part is present for signed envelopes
+// only, so sender is known to recipients only.
+
+// Envelope constants
+const (
+	EnvelopeVersion       = 1
+	EnvelopeHeaderLen     = 4 + 1 + 1 + BoxPubKeyLen + 2
+	EnvelopeSlotLen       = 32 + BoxNonceLen + BoxSharedKeyLen + 16
+	EnvelopeMaxRecipients = 1<<16 - 1
+)
+
+// Envelope flags
+const (
+	EnvelopeHidden = 1 << iota // recipient keys are not listed
+	EnvelopeSigned             // payload is signed by sender
+)
+
+var (
+	envelopeMagic      = []byte("bhxe")
+	envelopeSignPrefix = []byte("bhx-envelope")
+	envelopeSlotPrefix = []byte("bhx-envelope-slot")
+	envelopeWrapPrefix = []byte("bhx-envelope-wrap")
+)
+
+// Envelope errors
+var (
+	ErrEnvelope           = errors.New("invalid envelope")
+	ErrEnvelopeRecipient  = errors.New("not an envelope recipient")
+	ErrEnvelopeHidden     = errors.New("envelope recipients are hidden")
+	ErrEnvelopeSignature  = errors.New("invalid envelope signature")
+	ErrEnvelopeRecipients = errors.New("invalid envelope recipients count")
+)
+
+// EnvelopeOptions are optional envelope settings
+type EnvelopeOptions struct {
+	Sender *Keypair // signs envelope, if set
+	Hidden bool     // don't list recipient keys
+}
+
+type envelopeSlot struct {
+	id      [32]byte
+	wrapped []byte
+}
+
+// envelopeKeys returns slot tag and key wrapping key for shared key
+func envelopeKeys(shared *BoxSharedKey) (tag [32]byte, wrap BoxSharedKey) {
+	return Sha256H(envelopeSlotPrefix, shared[:]), BoxSharedKey(Sha256H(envelopeWrapPrefix, shared[:]))
+}
+
+// SealEnvelope encrypts message for recipients box keys
+func SealEnvelope(msg []byte, to []*BoxPub, opts *EnvelopeOptions) ([]byte, error) {
+	if opts == nil {
+		opts = new(EnvelopeOptions)
+	}
+
+	uniq := make(map[BoxPub]bool, len(to))
+	for _, pub := range to {
+		uniq[*pub] = true
+	}
+
+	if len(uniq) == 0 || len(uniq) > EnvelopeMaxRecipients {
+		return nil, fmt.Errorf("%w: %d", ErrEnvelopeRecipients, len(uniq))
+	}
+
+	ePub, ePriv, err := GenerateBoxKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	var contentKey BoxSharedKey
+	if _, err := io.ReadFull(rand.Reader, contentKey[:]); err != nil {
+		return nil, err
+	}
+
+	slots := make([]envelopeSlot, 0, len(uniq))
+	for pub := range uniq {
+		shared := GetSharedKey(&pub, ePriv)
+		tag, wrap := envelopeKeys(shared)
+
+		s := envelopeSlot{id: pub}
+		if opts.Hidden {
+			s.id = tag
+		}
+
+		if s.wrapped, err = EncryptAEAD(contentKey[:], wrap, ePub[:]); err != nil {
+			return nil, err
+		}
+
+		slots = append(slots, s)
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return bytes.Compare(slots[i].id[:], slots[j].id[:]) < 0
+	})
+
+	var flags byte
+	if opts.Hidden {
+		flags |= EnvelopeHidden
+	}
+
+	if opts.Sender != nil {
+		flags |= EnvelopeSigned
+	}
+
+	header := bytes.NewBuffer(make([]byte, 0, EnvelopeHeaderLen+len(slots)*EnvelopeSlotLen))
+	header.Write(envelopeMagic)
+	header.WriteByte(EnvelopeVersion)
+	header.WriteByte(flags)
+	header.Write(ePub[:])
+
+	count := make([]byte, 2)
+	PutUint16Le(count, uint16(len(slots)))
+	header.Write(count)
+	for _, s := range slots {
+		header.Write(s.id[:])
+		header.Write(s.wrapped)
+	}
+
+	inner := msg
+	if opts.Sender != nil {
+		hash := Sha256H(envelopeSignPrefix, header.Bytes(), msg)
+		inner = make([]byte, 0, PubKeyLen+SignSize+len(msg))
+		inner = append(inner, opts.Sender.pub[:]...)
+		inner = append(inner, opts.Sender.Sign(hash[:])[:]...)
+		inner = append(inner, msg...)
+	}
+
+	payload, err := EncryptAEAD(inner, contentKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return append(header.Bytes(), payload...), nil
+}
+
+// SealEnvelopeTo encrypts message for recipients ed25519 public keys
+func SealEnvelopeTo(msg []byte, to []*PubKey, opts *EnvelopeOptions) ([]byte, error) {
+	pubs := make([]*BoxPub, len(to))
+	for i, k := range to {
+		pub, err := BoxPubOf(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w: recipient %s", err, k)
+		}
+
+		pubs[i] = pub
+	}
+
+	return SealEnvelope(msg, pubs, opts)
+}
+
+// parseEnvelope returns flags, ephemeral key, slots and header length
+func parseEnvelope(data []byte) (flags byte, ePub *BoxPub, slots []envelopeSlot, n int, err error) {
+	if len(data) < EnvelopeHeaderLen || !bytes.Equal(data[:4], envelopeMagic) {
+		return 0, nil, nil, 0, ErrEnvelope
+	}
+
+	if data[4] != EnvelopeVersion {
+		return 0, nil, nil, 0, fmt.Errorf("%w: unknown version %d", ErrEnvelope, data[4])
+	}
+
+	flags = data[5]
+	if flags&^(EnvelopeHidden|EnvelopeSigned) != 0 {
+		return 0, nil, nil, 0, fmt.Errorf("%w: unknown flags %#x", ErrEnvelope, flags)
+	}
+
+	ePub = new(BoxPub)
+	copy(ePub[:], data[6:38])
+	count := int(Uint16Le(data[38:40]))
+	if count == 0 {
+		return 0, nil, nil, 0, fmt.Errorf("%w: %d", ErrEnvelopeRecipients, count)
+	}
+
+	n = EnvelopeHeaderLen + count*EnvelopeSlotLen
+	if len(data) < n {
+		return 0, nil, nil, 0, fmt.Errorf("%w: truncated slots", ErrEnvelope)
+	}
+
+	slots = make([]envelopeSlot, count)
+	for i := range slots {
+		s := data[EnvelopeHeaderLen+i*EnvelopeSlotLen:]
+		copy(slots[i].id[:], s[:32])
+		slots[i].wrapped = s[32:EnvelopeSlotLen]
+	}
+
+	return flags, ePub, slots, n, nil
+}
+
+// EnvelopeRecipients returns listed envelope recipients
+func EnvelopeRecipients(data []byte) ([]*BoxPub, error) {
+	flags, _, slots, _, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags&EnvelopeHidden != 0 {
+		return nil, ErrEnvelopeHidden
+	}
+
+	pubs := make([]*BoxPub, len(slots))
+	for i, s := range slots {
+		pub := BoxPub(s.id)
+		pubs[i] = &pub
+	}
+
+	return pubs, nil
+}
+
+// OpenEnvelope decrypts envelope with recipient box key, sender is nil
+// for unsigned envelopes
+func OpenEnvelope(data []byte, priv *BoxPriv) (msg []byte, sender *PubKey, err error) {
+	flags, ePub, slots, n, err := parseEnvelope(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shared := GetSharedKey(ePub, priv)
+	tag, wrap := envelopeKeys(shared)
+	id := tag
+	if flags&EnvelopeHidden == 0 {
+		pk, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		copy(id[:], pk)
+	}
+
+	i := sort.Search(len(slots), func(i int) bool {
+		return bytes.Compare(slots[i].id[:], id[:]) >= 0
+	})
+
+	if i == len(slots) || slots[i].id != id {
+		return nil, nil, ErrEnvelopeRecipient
+	}
+
+	key, err := DecryptAEAD(slots[i].wrapped, wrap, ePub[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var contentKey BoxSharedKey
+	copy(contentKey[:], key)
+	header := data[:n]
+	inner, err := DecryptAEAD(data[n:], contentKey, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if flags&EnvelopeSigned == 0 {
+		return inner, nil, nil
+	}
+
+	if len(inner) < PubKeyLen+SignSize {
+		return nil, nil, fmt.Errorf("%w: truncated signature", ErrEnvelope)
+	}
+
+	sender = new(PubKey)
+	copy(sender[:], inner[:PubKeyLen])
+	var sig SigData
+	copy(sig[:], inner[PubKeyLen:PubKeyLen+SignSize])
+	msg = inner[PubKeyLen+SignSize:]
+
+	hash := Sha256H(envelopeSignPrefix, header, msg)
+	if !Verify(sender, hash[:], &sig) {
+		return nil, nil, ErrEnvelopeSignature
+	}
+
+	return msg, sender, nil
+}
+
+// OpenEnvelope decrypts envelope with box key converted from the keypair
+func (k *Keypair) OpenEnvelope(data []byte) (msg []byte, sender *PubKey, err error) {
+	_, priv := k.BoxKeys()
+	return OpenEnvelope(data, priv)
+}
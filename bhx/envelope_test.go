@@ -0,0 +1,86 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	alice, _ := NewKeypair()
+	bob, _ := NewKeypair()
+	carol, _ := NewKeypair()
+	eve, _ := NewKeypair()
+
+	msg := []byte("team message")
+	for _, hidden := range []bool{false, true} {
+		opts := &EnvelopeOptions{Sender: alice, Hidden: hidden}
+		env, err := SealEnvelopeTo(msg, []*PubKey{&bob.pub, &carol.pub, &bob.pub}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, k := range []*Keypair{bob, carol} {
+			got, sender, err := k.OpenEnvelope(env)
+			if err != nil {
+				t.Fatalf("hidden %v: %v", hidden, err)
+			}
+
+			if !bytes.Equal(got, msg) || sender == nil || !sender.Equal(&alice.pub) {
+				t.Fatalf("hidden %v: unexpected message or sender", hidden)
+			}
+		}
+
+		if _, _, err := eve.OpenEnvelope(env); !errors.Is(err, ErrEnvelopeRecipient) {
+			t.Fatalf("want %v, got %v", ErrEnvelopeRecipient, err)
+		}
+
+		pubs, err := EnvelopeRecipients(env)
+		if hidden {
+			if !errors.Is(err, ErrEnvelopeHidden) {
+				t.Fatalf("want %v, got %v", ErrEnvelopeHidden, err)
+			}
+			continue
+		}
+
+		if err != nil || len(pubs) != 2 {
+			t.Fatalf("listed recipients %d: %v", len(pubs), err)
+		}
+
+		bobPub, _ := bob.BoxKeys()
+		if *pubs[0] != *bobPub && *pubs[1] != *bobPub {
+			t.Fatal("bob is not listed")
+		}
+	}
+}
+
+func TestEnvelopeUnsigned(t *testing.T) {
+	pub, priv, err := GenerateBoxKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := SealEnvelope([]byte("anonymous"), []*BoxPub{pub}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, sender, err := OpenEnvelope(env, priv)
+	if err != nil || sender != nil || string(msg) != "anonymous" {
+		t.Fatalf("unexpected result %q %v: %v", msg, sender, err)
+	}
+
+	// header is authenticated
+	env[5] |= EnvelopeSigned
+	if _, _, err := OpenEnvelope(env, priv); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("want %v, got %v", ErrDecrypt, err)
+	}
+
+	if _, _, err := OpenEnvelope(env[:EnvelopeHeaderLen], priv); !errors.Is(err, ErrEnvelope) {
+		t.Fatalf("want %v, got %v", ErrEnvelope, err)
+	}
+
+	if _, err := SealEnvelope(nil, nil, nil); !errors.Is(err, ErrEnvelopeRecipients) {
+		t.Fatalf("want %v, got %v", ErrEnvelopeRecipients, err)
+	}
+}
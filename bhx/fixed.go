@@ -0,0 +1,87 @@
+package bhx
+
+//go:generate go run hashgen.go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+)
+
+func compareFixed(a, b []byte) int { return bytes.Compare(a, b) }
+
+// prefixLen returns common bits prefix length, -1 if a equals b
+func prefixLen(a, b []byte) int {
+	for i := range a {
+		if x := a[i] ^ b[i]; x != 0 {
+			return 8*i + bits.LeadingZeros8(x)
+		}
+	}
+
+	return -1
+}
+
+// decodeFixedHex decodes hex string to dst, string must have exactly
+// len(dst) bytes
+func decodeFixedHex(dst []byte, s, name string) error {
+	b, err := HexDecodeStrict(s)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return unmarshalFixedBinary(dst, b, name)
+}
+
+func unmarshalFixedText(dst, text []byte, name string) error {
+	return decodeFixedHex(dst, string(text), name)
+}
+
+func unmarshalFixedBinary(dst, data []byte, name string) error {
+	if len(data) != len(dst) {
+		return fmt.Errorf("%w: %s needs %d bytes, got %d", ErrInvalidByteLen, name, len(dst), len(data))
+	}
+
+	copy(dst, data)
+	return nil
+}
+
+func marshalFixedJSON(s string) []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
+
+func unmarshalFixedJSON(dst, data []byte, name string) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return decodeFixedHex(dst, s, name)
+}
+
+// scanFixed scans raw bytes or hex string, NULL is scanned as zero value
+func scanFixed(dst []byte, src interface{}, name string) error {
+	switch v := src.(type) {
+	case nil:
+		for i := range dst {
+			dst[i] = 0
+		}
+		return nil
+	case []byte:
+		if len(v) == len(dst) {
+			copy(dst, v)
+			return nil
+		}
+
+		return decodeFixedHex(dst, string(v), name)
+	case string:
+		return decodeFixedHex(dst, v, name)
+	}
+
+	return fmt.Errorf("%s: cannot scan %T", name, src)
+}
@@ -0,0 +1,113 @@
+package bhx
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fixedType is implemented by all generated hash types
+type fixedType interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+	json.Marshaler
+	json.Unmarshaler
+	sql.Scanner
+	driver.Valuer
+}
+
+var (
+	_ fixedType = new(Hash160)
+	_ fixedType = new(Hash256)
+	_ fixedType = new(Hash512)
+)
+
+func TestFixedEncoding(t *testing.T) {
+	var h Hash160
+	rand.Read(h[:])
+
+	text, _ := h.MarshalText()
+	var h2 Hash160
+	if err := h2.UnmarshalText(text); err != nil || h2 != h {
+		t.Fatalf("text round trip: %v", err)
+	}
+
+	data, err := json.Marshal(map[string]Hash160{"id": h})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]Hash160
+	if err := json.Unmarshal(data, &m); err != nil || m["id"] != h {
+		t.Fatalf("json round trip %s: %v", data, err)
+	}
+
+	var h3 Hash512
+	rand.Read(h3[:])
+	v, _ := h3.Value()
+	var h4 Hash512
+	if err := h4.Scan(v); err != nil || h4 != h3 {
+		t.Fatalf("sql round trip: %v", err)
+	}
+
+	if err := h4.Scan(h3.String()); err != nil || h4 != h3 {
+		t.Fatalf("sql hex scan: %v", err)
+	}
+
+	bin, _ := h3.MarshalBinary()
+	if err := h4.UnmarshalBinary(bin[1:]); !errors.Is(err, ErrInvalidByteLen) {
+		t.Fatalf("want %v, got %v", ErrInvalidByteLen, err)
+	}
+
+	if err := h4.Scan(42); err == nil {
+		t.Fatal("int is scanned")
+	}
+}
+
+func TestFixedStrictHex(t *testing.T) {
+	valid := strings.Repeat("ab", 20)
+	for _, s := range []string{valid, "0x" + valid, strings.ToUpper(valid)} {
+		var h Hash160
+		if err := h.UnmarshalText([]byte(s)); err != nil || h[0] != 0xab {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+
+	for s, want := range map[string]string{
+		valid[:39]:                     "odd length",
+		valid[:38]:                     "needs 20 bytes, got 19",
+		"0X" + valid:                   "prefix must be 0x",
+		valid[:10] + "zz" + valid[12:]: "invalid character 'z' at offset 10",
+	} {
+		var h Hash160
+		err := h.UnmarshalJSON([]byte(`"` + s + `"`))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: want error %q, got %v", s, want, err)
+		}
+	}
+}
+
+func TestFixedPrefixLen(t *testing.T) {
+	var a, b Hash256
+	if a.PrefixLen(b) != -1 {
+		t.Fatal("equal hashes have prefix")
+	}
+
+	b[2] = 0x10
+	if a.PrefixLen(b) != 19 {
+		t.Fatalf("want 19, got %d", a.PrefixLen(b))
+	}
+
+	var c, d Hash512
+	d[63] = 1
+	if c.PrefixLen(d) != 511 || !c.Empty() || c.Equal(d) || !c.Less(d) {
+		t.Fatal("unexpected Hash512 comparison")
+	}
+}
@@ -1,64 +1,31 @@
 package bhx
 
 import (
-	"bytes"
-	"encoding/hex"
-	mrand "math/rand"
-	"fmt"
-	"io"
-	"math/big" 
-	"os"
+	"context"
+	"math/big"
+	"slices"
 
 	"golang.org/x/crypto/sha3"
 )
 
-// Hash256 is 32-byte hash value
-type Hash256 [32]byte
-
 // EmptyHash256 returns zero hash
 func EmptyHash256() Hash256 { return Hash256{} }
 
-// SetBytes copy given byte slice to hash value
-func (h *Hash256) SetBytes(b []byte) {
-	copy(h[:], b[:])
-}
-
-// Bytes convert hash result to byte slice
-func (h Hash256) Bytes() []byte {
-	return h[:]
-}
-
-func (h Hash256) String() string {
-	return fmt.Sprintf("0x%s", hex.EncodeToString(h.Bytes()))
-}
-
-// Equal returns true, if hashes is equal
-func (h Hash256) Equal(h2 Hash256) bool {
-	return bytes.Equal(h[:], h2[:])
-}
-
-// Empty returns if hash is zero
-func (h Hash256) Empty() bool { return h.Equal(Hash256{}) }
-
-// Xor operations for hashes
-func (h Hash256) Xor(other Hash256) (ret Hash256) {
-	for i := 0; i < 32; i++ {
-		ret[i] = h[i] ^ other[i]
-	}
-	return ret
-}
-
-// PrefixLen Возвращает длину префикса ID в сравнении с other
-func (h Hash256) PrefixLen(other Hash256) int {
-	distance := h.Xor(other)
-	for i := 0; i < 32; i++ {
-		for j := 0; j < 8; j++ {
-			if (distance[i]>>uint8(7-j))&0x1 != 0 {
-				return 8*i + j
+// XorCompare returns comparison function, which orders hashes by XOR
+// distance to target
+func XorCompare(target Hash256) func(a, b Hash256) int {
+	return func(a, b Hash256) int {
+		for i := range target {
+			if da, db := a[i]^target[i], b[i]^target[i]; da != db {
+				if da < db {
+					return -1
+				}
+				return 1
 			}
 		}
+
+		return 0
 	}
-	return -1
 }
 
 // Distance is scalar XOR for hashes
@@ -83,48 +50,24 @@ func (h Hash256) SetBigInt(i *big.Int) Hash256 {
 	return h
 }
 
-// SortHash256 sorts 256-bit hashes
+// SortHash256 sorts 256-bit hashes in ascending byte order
 func SortHash256(a []Hash256) []Hash256 {
-	if len(a) < 2 {
-		return a
-	}
-
-	l, r := 0, len(a)-1
-
-	pivIndex := mrand.Int() % len(a)
-
-	a[pivIndex], a[r] = a[r], a[pivIndex]
-	for i := range a {
-		if a[i].ToBigInt().Cmp(a[r].ToBigInt()) < 0 {
-			a[i], a[l] = a[l], a[i]
-			l++
-		}
-	}
-
-	a[l], a[r] = a[r], a[l]
-	SortHash256(a[:l])
-	SortHash256(a[l+1:])
-
+	slices.SortFunc(a, Hash256.Compare)
 	return a
 }
 
-// FileSha256 returns SHA3-256 hash of given file
-func FileSha256(path string) (fh Hash256, err error) {
-	fd, err := os.Open(path)
-	if err != nil {
-		return
-	}
-
-	defer fd.Close()
+// Hash256Slice attaches sort.Interface to []Hash256, sorting in ascending
+// byte order
+type Hash256Slice []Hash256
 
-	h := sha3.New256()
-	_, err = io.Copy(h, fd)
-	if err != nil {
-		return
-	}
+func (s Hash256Slice) Len() int           { return len(s) }
+func (s Hash256Slice) Less(i, j int) bool { return s[i].Less(s[j]) }
+func (s Hash256Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
-	copy(fh[:], h.Sum(nil))
-	return
+// FileSha256 returns SHA3-256 hash of given file (not SHA2-256, despite the
+// name), see HashFile
+func FileSha256(path string) (Hash256, error) {
+	return HashFile(context.Background(), HashSHA3_256, path, nil)
 }
 
 // Sha256H calculate SHA3-256 hash and returns it as Hash256
@@ -2,6 +2,9 @@
 
 import (
 	"crypto/rand"
+	mrand "math/rand"
+	"slices"
+	"sort"
 	"testing"
 )
 
@@ -18,17 +21,71 @@ func bubHash256sort(a []Hash256) []Hash256 {
 	return a
 }
 
+// bigIntQsort is former SortHash256 implementation
+func bigIntQsort(a []Hash256) []Hash256 {
+	if len(a) < 2 {
+		return a
+	}
+
+	l, r := 0, len(a)-1
+	pivIndex := mrand.Int() % len(a)
+	a[pivIndex], a[r] = a[r], a[pivIndex]
+	for i := range a {
+		if a[i].ToBigInt().Cmp(a[r].ToBigInt()) < 0 {
+			a[i], a[l] = a[l], a[i]
+			l++
+		}
+	}
+
+	a[l], a[r] = a[r], a[l]
+	bigIntQsort(a[:l])
+	bigIntQsort(a[l+1:])
+	return a
+}
+
+func randomHashes(n, distinct int) []Hash256 {
+	uniq := make([]Hash256, distinct)
+	for i := range uniq {
+		rand.Read(uniq[i][:])
+	}
+
+	a := make([]Hash256, n)
+	for i := range a {
+		a[i] = uniq[mrand.Intn(distinct)]
+	}
+
+	return a
+}
+
 func BenchmarkQsort(b *testing.B) {
-	a := []Hash256{}
-	for i := 0; i < 20; i++ {
-		var h Hash256
-		rand.Read(h[:])
-		a = append(a, h)
+	inputs := []struct {
+		name string
+		data []Hash256
+	}{
+		{"20", randomHashes(20, 20)},
+		{"1000", randomHashes(1000, 1000)},
+		{"1000-dups", randomHashes(1000, 10)},
 	}
 
-	for i := 0; i < b.N; i++ {
-		b := a[:]
-		b = SortHash256(b)
+	sorts := []struct {
+		name string
+		sort func([]Hash256)
+	}{
+		{"bigint", func(a []Hash256) { bigIntQsort(a) }},
+		{"SortHash256", func(a []Hash256) { SortHash256(a) }},
+		{"sort.Sort", func(a []Hash256) { sort.Sort(Hash256Slice(a)) }},
+	}
+
+	for _, in := range inputs {
+		buf := make([]Hash256, len(in.data))
+		for _, s := range sorts {
+			b.Run(s.name+"-"+in.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					copy(buf, in.data)
+					s.sort(buf)
+				}
+			})
+		}
 	}
 }
 
@@ -45,3 +102,69 @@ func BenchmarkBsort(b *testing.B) {
 		b = bubHash256sort(b)
 	}
 }
+
+func TestHash256Compare(t *testing.T) {
+	a := randomHashes(200, 50)
+	want := bigIntQsort(slices.Clone(a))
+	if got := SortHash256(slices.Clone(a)); !slices.Equal(got, want) {
+		t.Fatal("SortHash256 order differs from numeric order")
+	}
+
+	got := slices.Clone(a)
+	sort.Sort(Hash256Slice(got))
+	if !slices.Equal(got, want) {
+		t.Fatal("Hash256Slice order differs from numeric order")
+	}
+
+	for i := 1; i < len(want); i++ {
+		c := want[i-1].ToBigInt().Cmp(want[i].ToBigInt())
+		if want[i-1].Compare(want[i]) != c || want[i-1].Less(want[i]) != (c < 0) {
+			t.Fatal("Compare differs from numeric comparison")
+		}
+	}
+}
+
+func TestXorCompare(t *testing.T) {
+	var target Hash256
+	rand.Read(target[:])
+	a := randomHashes(100, 100)
+	slices.SortFunc(a, XorCompare(target))
+	for i := 1; i < len(a); i++ {
+		if a[i-1].Xor(target).ToBigInt().Cmp(a[i].Xor(target).ToBigInt()) > 0 {
+			t.Fatal("hashes are not ordered by XOR distance")
+		}
+	}
+
+	if XorCompare(target)(target, a[0]) >= 0 {
+		t.Fatal("target itself is not the closest")
+	}
+}
+
+func TestHash256Set(t *testing.T) {
+	a := randomHashes(100, 30)
+	s := NewHash256Set(a...)
+	if s.Len() > 30 || !slices.IsSortedFunc(s.Items(), Hash256.Compare) {
+		t.Fatal("set is not sorted and unique")
+	}
+
+	for _, h := range a {
+		if !s.Contains(h) || s.Insert(h) {
+			t.Fatal("set misses inserted hash")
+		}
+	}
+
+	var h Hash256
+	rand.Read(h[:])
+	if s.Contains(h) || !s.Insert(h) || !s.Contains(h) {
+		t.Fatal("new hash is not inserted")
+	}
+
+	if !s.Remove(h) || s.Remove(h) || s.Contains(h) {
+		t.Fatal("unexpected remove result")
+	}
+
+	var empty Hash256Set
+	if empty.Contains(h) || !empty.Insert(h) || empty.Len() != 1 {
+		t.Fatal("zero set is not usable")
+	}
+}
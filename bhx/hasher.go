@@ -0,0 +1,195 @@
+package bhx
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
+)
+
+// HashAlg is 256-bit hash algorithm ID, IDs are multicodec codes, so
+// they are used in multihash encoding as is
+type HashAlg uint64
+
+// Hash algorithms
+const (
+	HashSHA2_256    HashAlg = 0x12
+	HashSHA3_256    HashAlg = 0x16
+	HashBLAKE3      HashAlg = 0x1e
+	HashBLAKE2b_256 HashAlg = 0xb220
+)
+
+// hashReadSize is HashReader read buffer size
+const hashReadSize = 1 << 20
+
+// Hasher errors
+var (
+	ErrHashAlg   = errors.New("unknown hash algorithm")
+	ErrMultihash = errors.New("invalid multihash")
+)
+
+func (a HashAlg) String() string {
+	switch a {
+	case HashSHA2_256:
+		return "sha2-256"
+	case HashSHA3_256:
+		return "sha3-256"
+	case HashBLAKE3:
+		return "blake3"
+	case HashBLAKE2b_256:
+		return "blake2b-256"
+	}
+
+	return "HashAlg(0x" + strconv.FormatUint(uint64(a), 16) + ")"
+}
+
+// Hasher is hash.Hash with 32-byte output, write data to it with io.Writer
+// interface and get result with Sum256
+type Hasher interface {
+	hash.Hash
+	Alg() HashAlg
+	Sum256() Hash256
+}
+
+type hasher struct {
+	hash.Hash
+	alg HashAlg
+}
+
+func (h *hasher) Alg() HashAlg { return h.alg }
+
+func (h *hasher) Sum256() (sum Hash256) {
+	copy(sum[:], h.Sum(nil))
+	return
+}
+
+// NewHasher returns hasher for algorithm
+func NewHasher(alg HashAlg) (Hasher, error) {
+	var h hash.Hash
+	switch alg {
+	case HashSHA2_256:
+		h = sha256.New()
+	case HashSHA3_256:
+		h = sha3.New256()
+	case HashBLAKE3:
+		h = blake3.New(32, nil)
+	case HashBLAKE2b_256:
+		h, _ = blake2b.New256(nil)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrHashAlg, alg)
+	}
+
+	return &hasher{Hash: h, alg: alg}, nil
+}
+
+// HashBytes returns hash of data
+func HashBytes(alg HashAlg, data ...[]byte) (Hash256, error) {
+	h, err := NewHasher(alg)
+	if err != nil {
+		return Hash256{}, err
+	}
+
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	return h.Sum256(), nil
+}
+
+// Multihash returns self-describing hash encoding:
+//
+//	uvarint alg | uvarint digest length (32) | digest
+func (h Hash256) Multihash(alg HashAlg) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	b := append([]byte{}, tmp[:binary.PutUvarint(tmp[:], uint64(alg))]...)
+	b = append(b, byte(len(h)))
+	return append(b, h[:]...)
+}
+
+// ParseMultihash decodes multihash of known 256-bit algorithm
+func ParseMultihash(b []byte) (HashAlg, Hash256, error) {
+	code, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, Hash256{}, fmt.Errorf("%w: invalid algorithm code", ErrMultihash)
+	}
+
+	alg := HashAlg(code)
+	if _, err := NewHasher(alg); err != nil {
+		return 0, Hash256{}, err
+	}
+
+	var h Hash256
+	b = b[n:]
+	if len(b) != 1+len(h) || int(b[0]) != len(h) {
+		return 0, Hash256{}, fmt.Errorf("%w: digest must be %d bytes", ErrMultihash, len(h))
+	}
+
+	copy(h[:], b[1:])
+	return alg, h, nil
+}
+
+// HashProgress is called with processed and total bytes count, total is
+// -1 if unknown
+type HashProgress func(done, total int64)
+
+// HashReader hashes data from r until EOF or ctx cancellation, progress
+// may be nil
+func HashReader(ctx context.Context, alg HashAlg, r io.Reader, progress HashProgress) (Hash256, error) {
+	return hashReader(ctx, alg, r, -1, progress)
+}
+
+// HashFile hashes file, progress may be nil
+func HashFile(ctx context.Context, alg HashAlg, path string, progress HashProgress) (Hash256, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return Hash256{}, err
+	}
+
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return Hash256{}, err
+	}
+
+	return hashReader(ctx, alg, fd, fi.Size(), progress)
+}
+
+func hashReader(ctx context.Context, alg HashAlg, r io.Reader, total int64, progress HashProgress) (Hash256, error) {
+	h, err := NewHasher(alg)
+	if err != nil {
+		return Hash256{}, err
+	}
+
+	buf := make([]byte, hashReadSize)
+	var done int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return Hash256{}, err
+		}
+
+		n, err := r.Read(buf)
+		h.Write(buf[:n])
+		done += int64(n)
+		if n > 0 && progress != nil {
+			progress(done, total)
+		}
+
+		if err == io.EOF {
+			return h.Sum256(), nil
+		}
+
+		if err != nil {
+			return Hash256{}, err
+		}
+	}
+}
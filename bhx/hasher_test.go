@@ -0,0 +1,116 @@
+package bhx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var hasherVectors = map[HashAlg]string{
+	HashSHA2_256:    "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	HashSHA3_256:    "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+	HashBLAKE3:      "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85",
+	HashBLAKE2b_256: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+}
+
+func TestHasher(t *testing.T) {
+	for alg, want := range hasherVectors {
+		h, err := HashBytes(alg, []byte("a"), []byte("bc"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if HexEnc(h[:]) != want {
+			t.Fatalf("%s: want %s, got %s", alg, want, h)
+		}
+
+		mh := h.Multihash(alg)
+		gotAlg, gotHash, err := ParseMultihash(mh)
+		if err != nil || gotAlg != alg || gotHash != h {
+			t.Fatalf("%s: multihash round trip: %v", alg, err)
+		}
+	}
+
+	// sha2-256 multihash from the spec
+	mh := HexDec("12209cbc07c3f991725836a3aa2a581ca2029198aa420b9d99bc0e131d9f3e2cbe47")
+	if alg, _, err := ParseMultihash(mh); err != nil || alg != HashSHA2_256 {
+		t.Fatalf("unexpected multihash alg %s: %v", alg, err)
+	}
+
+	if _, _, err := ParseMultihash(mh[:len(mh)-1]); !errors.Is(err, ErrMultihash) {
+		t.Fatalf("want %v, got %v", ErrMultihash, err)
+	}
+
+	if _, err := NewHasher(0x11); !errors.Is(err, ErrHashAlg) {
+		t.Fatalf("want %v, got %v", ErrHashAlg, err)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	data := bytes.Repeat([]byte("bhx"), hashReadSize)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	h, err := HashFile(context.Background(), HashSHA3_256, path, func(done, total int64) {
+		calls++
+		if total != int64(len(data)) || done > total {
+			t.Fatalf("unexpected progress %d/%d", done, total)
+		}
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls < 3 {
+		t.Fatalf("progress called %d times", calls)
+	}
+
+	if fh, _ := FileSha256(path); fh != h {
+		t.Fatal("HashFile with sha3-256 doesn't match FileSha256")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = HashReader(ctx, HashBLAKE3, bytes.NewReader(data), func(done, total int64) {
+		if total != -1 {
+			t.Fatalf("unexpected total %d", total)
+		}
+		cancel()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestFileSha256(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	data := bytes.Repeat([]byte("bhx"), hashReadSize)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fh, err := FileSha256(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := HashFile(context.Background(), HashSHA3_256, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fh != h || fh != Sha256H(data) {
+		t.Fatalf("FileSha256 %s, HashFile %s, Sha256H %s", fh, h, Sha256H(data))
+	}
+
+	if _, err := FileSha256(path + ".missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want %v, got %v", os.ErrNotExist, err)
+	}
+}
@@ -0,0 +1,166 @@
+//go:build ignore
+
+// hashgen generates fixed-size hash types and encoding methods of key
+// types, run it with go generate.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"log"
+	"os"
+	"text/template"
+)
+
+var (
+	hashTypes = []fixedType{
+		{Name: "Hash160", Size: 20, Recv: "h", Prefix: true},
+		{Name: "Hash256", Size: 32, Recv: "h", Prefix: true},
+		{Name: "Hash512", Size: 64, Recv: "h", Prefix: true},
+	}
+
+	// key types are declared in sign.go
+	keyTypes = []fixedType{
+		{Name: "PubKey", Size: 32, Recv: "k"},
+		{Name: "PrivKey", Size: 64, Recv: "k"},
+		{Name: "SigData", Size: 64, Recv: "k"},
+	}
+)
+
+// fixedType is generated type, Prefix adds 0x to text encoding
+type fixedType struct {
+	Name   string
+	Size   int
+	Recv   string
+	Prefix bool
+}
+
+var tmpl = template.Must(template.New("header").Parse(`// Code generated by hashgen.go; DO NOT EDIT.
+
+package bhx
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+)
+`))
+
+func init() {
+	template.Must(tmpl.New("hash").Parse(`
+// {{.Name}} is {{.Size}}-byte hash value
+type {{.Name}} [{{.Size}}]byte
+
+// SetBytes copy given byte slice to hash value
+func (h *{{.Name}}) SetBytes(b []byte) {
+	copy(h[:], b[:])
+}
+
+// Bytes convert hash result to byte slice
+func (h {{.Name}}) Bytes() []byte {
+	return h[:]
+}
+
+func (h {{.Name}}) String() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// Equal returns true, if hashes is equal
+func (h {{.Name}}) Equal(h2 {{.Name}}) bool { return h == h2 }
+
+// Empty returns if hash is zero
+func (h {{.Name}}) Empty() bool { return h == {{.Name}}{} }
+
+// Compare returns -1, 0 or +1 comparing hashes as big-endian numbers,
+// it can be used with slices.SortFunc
+func (h {{.Name}}) Compare(other {{.Name}}) int { return compareFixed(h[:], other[:]) }
+
+// Less returns true, if h < other
+func (h {{.Name}}) Less(other {{.Name}}) bool { return h.Compare(other) < 0 }
+
+// Xor operations for hashes
+func (h {{.Name}}) Xor(other {{.Name}}) (ret {{.Name}}) {
+	for i := range h {
+		ret[i] = h[i] ^ other[i]
+	}
+	return ret
+}
+
+// PrefixLen returns common prefix length of h and other in bits,
+// -1 if hashes are equal
+func (h {{.Name}}) PrefixLen(other {{.Name}}) int { return prefixLen(h[:], other[:]) }
+{{template "encoding" .}}`))
+
+	template.Must(tmpl.New("encoding").Parse(`
+// MarshalText implements encoding.TextMarshaler
+func ({{.Recv}} {{.Name}}) MarshalText() ([]byte, error) {
+	return []byte({{if .Prefix}}"0x" + {{end}}hex.EncodeToString({{.Recv}}[:])), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, hex string must have
+// exactly {{.Size}} bytes
+func ({{.Recv}} *{{.Name}}) UnmarshalText(text []byte) error {
+	return unmarshalFixedText({{.Recv}}[:], text, "{{.Name}}")
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func ({{.Recv}} {{.Name}}) MarshalBinary() ([]byte, error) { return {{.Recv}}[:], nil }
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+func ({{.Recv}} *{{.Name}}) UnmarshalBinary(data []byte) error {
+	return unmarshalFixedBinary({{.Recv}}[:], data, "{{.Name}}")
+}
+
+// GobEncode implements gob.GobEncoder
+func ({{.Recv}} {{.Name}}) GobEncode() ([]byte, error) { return {{.Recv}}.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder
+func ({{.Recv}} *{{.Name}}) GobDecode(data []byte) error { return {{.Recv}}.UnmarshalBinary(data) }
+
+// MarshalJSON implements json.Marshaler
+func ({{.Recv}} {{.Name}}) MarshalJSON() ([]byte, error) {
+	text, _ := {{.Recv}}.MarshalText()
+	return marshalFixedJSON(string(text)), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, null is ignored
+func ({{.Recv}} *{{.Name}}) UnmarshalJSON(data []byte) error {
+	return unmarshalFixedJSON({{.Recv}}[:], data, "{{.Name}}")
+}
+
+// Scan implements sql.Scanner for raw bytes or hex string columns
+func ({{.Recv}} *{{.Name}}) Scan(src interface{}) error {
+	return scanFixed({{.Recv}}[:], src, "{{.Name}}")
+}
+
+// Value implements driver.Valuer, value is stored as raw bytes
+func ({{.Recv}} {{.Name}}) Value() (driver.Value, error) { return {{.Recv}}[:], nil }
+`))
+}
+
+// generate writes file with types generated by named template
+func generate(path, name string, types []fixedType) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "header", nil); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, t := range types {
+		if err := tmpl.ExecuteTemplate(&buf, name, t); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	generate("hashes_gen.go", "hash", hashTypes)
+	generate("keys_gen.go", "encoding", keyTypes)
+}
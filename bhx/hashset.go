@@ -0,0 +1,50 @@
+package bhx
+
+import "slices"
+
+// Hash256Set is sorted set of hashes, zero value is empty set. It's not
+// safe for concurrent use.
+type Hash256Set struct {
+	items []Hash256
+}
+
+// NewHash256Set returns set of given hashes
+func NewHash256Set(hashes ...Hash256) *Hash256Set {
+	items := slices.Clone(hashes)
+	slices.SortFunc(items, Hash256.Compare)
+	return &Hash256Set{items: slices.Compact(items)}
+}
+
+// Len returns set size
+func (s *Hash256Set) Len() int { return len(s.items) }
+
+// Contains returns true, if hash is in the set
+func (s *Hash256Set) Contains(h Hash256) bool {
+	_, ok := slices.BinarySearchFunc(s.items, h, Hash256.Compare)
+	return ok
+}
+
+// Insert adds hash, returns false if it's already in the set
+func (s *Hash256Set) Insert(h Hash256) bool {
+	i, ok := slices.BinarySearchFunc(s.items, h, Hash256.Compare)
+	if ok {
+		return false
+	}
+
+	s.items = slices.Insert(s.items, i, h)
+	return true
+}
+
+// Remove removes hash, returns false if it isn't in the set
+func (s *Hash256Set) Remove(h Hash256) bool {
+	i, ok := slices.BinarySearchFunc(s.items, h, Hash256.Compare)
+	if !ok {
+		return false
+	}
+
+	s.items = slices.Delete(s.items, i, i+1)
+	return true
+}
+
+// Items returns sorted copy of set hashes
+func (s *Hash256Set) Items() []Hash256 { return slices.Clone(s.items) }
@@ -0,0 +1,158 @@
+package bhx
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// SLIP-0010 hierarchical deterministic key derivation. Ed25519 and
+// Curve25519 support hardened derivation only, so every path index must
+// be hardened, e.g. "m/44'/0'/1'" (H and h suffixes are accepted too).
+
+// HD constants
+const (
+	HDHardened   = uint32(1) << 31
+	HDMinSeedLen = 16
+	HDMaxSeedLen = 64
+)
+
+var (
+	hdEd25519Seed    = []byte("ed25519 seed")
+	hdCurve25519Seed = []byte("curve25519 seed")
+)
+
+// HD errors
+var (
+	ErrHDSeed        = errors.New("invalid HD seed length")
+	ErrHDPath        = errors.New("invalid HD derivation path")
+	ErrHDNotHardened = errors.New("HD path index must be hardened")
+)
+
+// hdNode is SLIP-0010 extended private key
+type hdNode struct {
+	key       [32]byte
+	chainCode [32]byte
+}
+
+func hdSplit(key, data []byte) *hdNode {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := new(hdNode)
+	copy(n.key[:], sum[:32])
+	copy(n.chainCode[:], sum[32:])
+	return n
+}
+
+func hdMaster(curveSeed, seed []byte) (*hdNode, error) {
+	if len(seed) < HDMinSeedLen || len(seed) > HDMaxSeedLen {
+		return nil, fmt.Errorf("%w: %d", ErrHDSeed, len(seed))
+	}
+
+	return hdSplit(curveSeed, seed), nil
+}
+
+// child returns hardened child node
+func (n *hdNode) child(index uint32) (*hdNode, error) {
+	if index < HDHardened {
+		return nil, ErrHDNotHardened
+	}
+
+	data := make([]byte, 1+32+4)
+	copy(data[1:33], n.key[:])
+	data[33] = byte(index >> 24)
+	data[34] = byte(index >> 16)
+	data[35] = byte(index >> 8)
+	data[36] = byte(index)
+	return hdSplit(n.chainCode[:], data), nil
+}
+
+func hdDerive(curveSeed, seed []byte, path string) (*hdNode, error) {
+	indexes, err := ParseHDPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := hdMaster(curveSeed, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range indexes {
+		if n, err = n.child(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// ParseHDPath parses derivation path like "m/44'/0'/1'" to indexes,
+// only hardened indexes are allowed
+func ParseHDPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("%w: %q must start with m", ErrHDPath, path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		idx := strings.TrimRight(p, "'Hh")
+		if len(p)-len(idx) != 1 {
+			return nil, fmt.Errorf("%w: %q", ErrHDNotHardened, p)
+		}
+
+		i, err := strconv.ParseUint(idx, 10, 32)
+		if err != nil || i >= uint64(HDHardened) || (len(idx) > 1 && idx[0] == '0') {
+			return nil, fmt.Errorf("%w: invalid index %q", ErrHDPath, p)
+		}
+
+		indexes = append(indexes, uint32(i)|HDHardened)
+	}
+
+	return indexes, nil
+}
+
+// DeriveKeypair derives ed25519 keypair from seed with SLIP-0010 path
+func DeriveKeypair(seed []byte, path string) (*Keypair, error) {
+	n, err := hdDerive(hdEd25519Seed, seed, path)
+	if err != nil {
+		return nil, err
+	}
+
+	priv := PrivKeyFromSeed(n.key[:])
+	return &Keypair{
+		pub:  *PubKeyOf(priv),
+		priv: *priv,
+	}, nil
+}
+
+// DeriveBoxKeys derives NaCl box (Curve25519) keys from seed with
+// SLIP-0010 path
+func DeriveBoxKeys(seed []byte, path string) (*BoxPub, *BoxPriv, error) {
+	n, err := hdDerive(hdCurve25519Seed, seed, path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		pub  BoxPub
+		priv BoxPriv
+	)
+
+	copy(priv[:], n.key[:])
+	pk, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copy(pub[:], pk)
+	return &pub, &priv, nil
+}
@@ -2,11 +2,17 @@ package bhx
 
 import (
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"strings"
 )
 
+// ErrInvalidHex is returned for malformed hex string
+var ErrInvalidHex = errors.New("invalid hex string")
+
 // HexDec decode the hex string (supports 0x prefix)
-// if the string is not valid returns nil
+// if the string is not valid returns nil, use HexDecodeStrict to get
+// the error
 func HexDec(str string) []byte {
 	b, err := hex.DecodeString(strings.TrimPrefix(str, "0x"))
 	if err != nil {
@@ -22,8 +28,82 @@ func HexEnc(b []byte) string { return hex.EncodeToString(b) }
 // HexEnc0x like HexEnc, but add 0x prefix
 func HexEnc0x(b []byte) string { return "0x" + HexEnc(b) }
 
-// DecodeHash256 decode string to Hash256
+// DecodeHash256 decode string to Hash256, invalid string gives zero or
+// partial hash, use ParseHash256 to check the string
 func DecodeHash256(str string) (h Hash256) {
 	copy(h[:], HexDec(str))
 	return h
 }
+
+// HexDecodeStrict decodes hex string with optional 0x prefix (uppercase 0X
+// is rejected), unlike HexDec it returns error with offset of invalid
+// character in the string
+func HexDecodeStrict(s string) ([]byte, error) {
+	off := 0
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		if s[1] == 'X' {
+			return nil, fmt.Errorf("%w: prefix must be 0x", ErrInvalidHex)
+		}
+
+		off = 2
+	}
+
+	if (len(s)-off)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd length %d", ErrInvalidHex, len(s)-off)
+	}
+
+	b := make([]byte, (len(s)-off)/2)
+	for i := range b {
+		hi, ok1 := fromHexChar(s[off+2*i])
+		lo, ok2 := fromHexChar(s[off+2*i+1])
+		switch {
+		case !ok1:
+			return nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidHex, s[off+2*i], off+2*i)
+		case !ok2:
+			return nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidHex, s[off+2*i+1], off+2*i+1)
+		}
+
+		b[i] = hi<<4 | lo
+	}
+
+	return b, nil
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+
+	return 0, false
+}
+
+// ParseHash256 decodes hex string with exactly 32 bytes
+func ParseHash256(str string) (h Hash256, err error) {
+	err = decodeFixedHex(h[:], str, "Hash256")
+	return
+}
+
+// ParsePubKey decodes hex-encoded public key
+func ParsePubKey(str string) (*PubKey, error) {
+	k := new(PubKey)
+	if err := decodeFixedHex(k[:], str, "PubKey"); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// ParseSigData decodes hex-encoded signature
+func ParseSigData(str string) (*SigData, error) {
+	sig := new(SigData)
+	if err := decodeFixedHex(sig[:], str, "SigData"); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
@@ -0,0 +1,85 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHexDecodeStrict(t *testing.T) {
+	for in, want := range map[string][]byte{
+		"":       {},
+		"0x":     {},
+		"00ff":   {0x00, 0xff},
+		"0x00FF": {0x00, 0xff},
+	} {
+		got, err := HexDecodeStrict(in)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%q: got %x, %v", in, got, err)
+		}
+	}
+
+	for in, want := range map[string]string{
+		"abc":    "odd length 3",
+		"0xabc":  "odd length 3",
+		"0Xab":   "prefix must be 0x",
+		"ab g00": "invalid character ' ' at offset 2",
+		"0xab0g": "invalid character 'g' at offset 5",
+	} {
+		_, err := HexDecodeStrict(in)
+		if !errors.Is(err, ErrInvalidHex) || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: want error %q, got %v", in, want, err)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k, _ := NewKeypair()
+	pub, err := ParsePubKey(k.pub.String())
+	if err != nil || !pub.Equal(&k.pub) {
+		t.Fatalf("ParsePubKey: %v", err)
+	}
+
+	// typo in public key
+	typo := k.pub.String()[:10] + "o" + k.pub.String()[11:]
+	if _, err := ParsePubKey(typo); !errors.Is(err, ErrInvalidHex) || !strings.Contains(err.Error(), "offset 10") {
+		t.Fatalf("want offset error, got %v", err)
+	}
+
+	sig := k.Sign([]byte("msg"))
+	if got, err := ParseSigData("0x" + sig.String()); err != nil || *got != *sig {
+		t.Fatalf("ParseSigData: %v", err)
+	}
+
+	if _, err := ParseSigData(sig.String()[:126]); !errors.Is(err, ErrInvalidByteLen) {
+		t.Fatalf("want %v, got %v", ErrInvalidByteLen, err)
+	}
+
+	h := k.pub.GetHash()
+	if got, err := ParseHash256(h.String()); err != nil || got != h {
+		t.Fatalf("ParseHash256: %v", err)
+	}
+
+	if _, err := ParseHash256(""); !errors.Is(err, ErrInvalidByteLen) {
+		t.Fatalf("want %v, got %v", ErrInvalidByteLen, err)
+	}
+}
+
+func TestImportJSONStrict(t *testing.T) {
+	acc, err := MakeNewAccount("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := acc.GetAccount().ExportJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub := acc.Keys.pub.String()
+	bad := bytes.Replace(data, []byte(pub), []byte(pub[:len(pub)-2]), 1)
+	if _, err := new(Account).ImportJSON(bad); !errors.Is(err, ErrAccountData) || !strings.Contains(err.Error(), "public_key") {
+		t.Fatalf("truncated public key accepted: %v", err)
+	}
+}
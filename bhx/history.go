@@ -0,0 +1,412 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Revocation is signed statement, that all versions of the account
+// with given public key are no longer valid
+type Revocation struct {
+	pub       PubKey
+	reason    string
+	timestamp uint32
+	sign      SigData
+}
+
+// Revocation constants
+const (
+	RevocationHeaderLen    = PubKeyLen + 4 + SignSize
+	RevocationMaxReasonLen = 1024
+)
+
+var (
+	revocationPrefix = []byte("bhx-revocation")
+	rotationPrefix   = []byte("bhx-rotation")
+)
+
+// History errors
+var (
+	ErrRevocation      = errors.New("invalid revocation")
+	ErrAccountRevoked  = errors.New("account is revoked")
+	ErrAccountUnknown  = errors.New("no valid account for public key")
+	ErrAccountFork     = errors.New("account history has conflicting versions")
+	ErrAccountStale    = errors.New("account version is stale")
+	ErrAccountBadChain = errors.New("account version does not follow previous one")
+	ErrRotation        = errors.New("invalid key rotation")
+	ErrRotationFork    = errors.New("key has conflicting rotations")
+	ErrKeyRetired      = errors.New("account is signed by retired key")
+)
+
+// Revoke returns revocation signed with the keypair
+func (k *Keypair) Revoke(reason string) *Revocation {
+	r := &Revocation{
+		pub:       k.pub,
+		reason:    reason,
+		timestamp: uint32(time.Now().Unix()),
+	}
+
+	hash := r.GetHash()
+	r.sign = *(k.Sign(hash[:]))
+	return r
+}
+
+// PublicKey returns revoked public key
+func (r *Revocation) PublicKey() *PubKey {
+	k := new(PubKey)
+	copy(k[:], r.pub[:])
+	return k
+}
+
+// Reason returns revocation reason
+func (r *Revocation) Reason() string { return r.reason }
+
+// Timestamp returns revocation time
+func (r *Revocation) Timestamp() uint32 { return r.timestamp }
+
+// GetHash returns signed revocation hash
+func (r *Revocation) GetHash() Hash256 {
+	ts := make([]byte, 4)
+	PutUint32Le(ts, r.timestamp)
+	return Sha256H(revocationPrefix, r.pub[:], ts, []byte(r.reason))
+}
+
+// Verify revocation signature
+func (r *Revocation) Verify() bool {
+	hash := r.GetHash()
+	return Verify(&r.pub, hash[:], &r.sign)
+}
+
+// Bytes returns binary revocation data:
+//
+//	pub (32) | timestamp (4, LE) | signature (64) | uvarint len | reason
+func (r *Revocation) Bytes() []byte {
+	data := make([]byte, RevocationHeaderLen)
+	copy(data[:32], r.pub[:])
+	PutUint32Le(data[32:36], r.timestamp)
+	copy(data[36:], r.sign[:])
+
+	buf := bytes.NewBuffer(data)
+	writeUvarintString(buf, r.reason)
+	return buf.Bytes()
+}
+
+// SetBytes decode raw revocation
+func (r *Revocation) SetBytes(b []byte) (*Revocation, error) {
+	if len(b) < RevocationHeaderLen {
+		return nil, fmt.Errorf("%w: size %d < %d", ErrRevocation, len(b), RevocationHeaderLen)
+	}
+
+	rd := bytes.NewReader(b[RevocationHeaderLen:])
+	reason, err := readUvarintString(rd, RevocationMaxReasonLen, "reason")
+	if err != nil {
+		return nil, err
+	}
+
+	if rd.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrAccountTrailingData, rd.Len())
+	}
+
+	copy(r.pub[:], b[:32])
+	r.timestamp = Uint32Le(b[32:36])
+	copy(r.sign[:], b[36:RevocationHeaderLen])
+	r.reason = reason
+	return r, nil
+}
+
+// Rotation is hand-off of account identity from old key to the new one,
+// it's signed by both keys. Old key signatures made after rotation
+// time are not accepted.
+type Rotation struct {
+	old       PubKey
+	new       PubKey
+	timestamp uint32
+	oldSign   SigData
+	newSign   SigData
+}
+
+// RotationLen is binary rotation size
+const RotationLen = 2*PubKeyLen + 4 + 2*SignSize
+
+// RotateTo returns rotation from the keypair to next keypair
+func (k *Keypair) RotateTo(next *Keypair) *Rotation {
+	rot := &Rotation{
+		old:       k.pub,
+		new:       next.pub,
+		timestamp: uint32(time.Now().Unix()),
+	}
+
+	hash := rot.GetHash()
+	rot.oldSign = *(k.Sign(hash[:]))
+	rot.newSign = *(next.Sign(hash[:]))
+	return rot
+}
+
+// OldKey returns retired public key
+func (r *Rotation) OldKey() *PubKey {
+	k := new(PubKey)
+	copy(k[:], r.old[:])
+	return k
+}
+
+// NewKey returns new public key
+func (r *Rotation) NewKey() *PubKey {
+	k := new(PubKey)
+	copy(k[:], r.new[:])
+	return k
+}
+
+// Timestamp returns rotation time
+func (r *Rotation) Timestamp() uint32 { return r.timestamp }
+
+// GetHash returns signed rotation hash
+func (r *Rotation) GetHash() Hash256 {
+	ts := make([]byte, 4)
+	PutUint32Le(ts, r.timestamp)
+	return Sha256H(rotationPrefix, r.old[:], r.new[:], ts)
+}
+
+// Verify checks both rotation signatures
+func (r *Rotation) Verify() bool {
+	if r.old.Equal(&r.new) {
+		return false
+	}
+
+	hash := r.GetHash()
+	return Verify(&r.old, hash[:], &r.oldSign) && Verify(&r.new, hash[:], &r.newSign)
+}
+
+// Bytes returns binary rotation data:
+//
+//	old pub (32) | new pub (32) | timestamp (4, LE) | old sign (64) | new sign (64)
+func (r *Rotation) Bytes() []byte {
+	data := make([]byte, RotationLen)
+	copy(data[:32], r.old[:])
+	copy(data[32:64], r.new[:])
+	PutUint32Le(data[64:68], r.timestamp)
+	copy(data[68:132], r.oldSign[:])
+	copy(data[132:], r.newSign[:])
+	return data
+}
+
+// SetBytes decode raw rotation
+func (r *Rotation) SetBytes(b []byte) (*Rotation, error) {
+	if len(b) != RotationLen {
+		return nil, fmt.Errorf("%w: size %d != %d", ErrRotation, len(b), RotationLen)
+	}
+
+	copy(r.old[:], b[:32])
+	copy(r.new[:], b[32:64])
+	r.timestamp = Uint32Le(b[64:68])
+	copy(r.oldSign[:], b[68:132])
+	copy(r.newSign[:], b[132:])
+	return r, nil
+}
+
+// Resolver collects account versions, revocations and key rotations and
+// returns the latest valid version of the account. Resolver is not safe
+// for concurrent use.
+type Resolver struct {
+	accounts  map[PubKey]map[Hash256]*Account
+	revoked   map[PubKey]*Revocation
+	rotations map[PubKey]map[Hash256]*Rotation // by old key
+}
+
+// NewResolver returns empty account resolver
+func NewResolver() *Resolver {
+	return &Resolver{
+		accounts:  make(map[PubKey]map[Hash256]*Account),
+		revoked:   make(map[PubKey]*Revocation),
+		rotations: make(map[PubKey]map[Hash256]*Rotation),
+	}
+}
+
+// Add decodes and adds account version, see AddAccount
+func (r *Resolver) Add(blob []byte) (*Account, error) {
+	a, err := new(Account).SetBytes(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, r.AddAccount(a)
+}
+
+// AddAccount adds account version, versions with invalid signature
+// or without sequence (older than AccountVersion2) are rejected
+func (r *Resolver) AddAccount(a *Account) error {
+	if a.version < AccountVersion2 {
+		return fmt.Errorf("%w: %d", ErrAccountVersion, a.version)
+	}
+
+	if !a.Verify() {
+		return fmt.Errorf("%w: invalid signature", ErrAccountData)
+	}
+
+	versions := r.accounts[a.pub]
+	if versions == nil {
+		versions = make(map[Hash256]*Account)
+		r.accounts[a.pub] = versions
+	}
+
+	versions[a.GetHash()] = a
+	return nil
+}
+
+// AddRevocation adds signed revocation
+func (r *Resolver) AddRevocation(rev *Revocation) error {
+	if !rev.Verify() {
+		return fmt.Errorf("%w: invalid signature", ErrRevocation)
+	}
+
+	r.revoked[rev.pub] = rev
+	return nil
+}
+
+// AddRotation adds key rotation signed by both keys, rotations from
+// revoked key are rejected
+func (r *Resolver) AddRotation(rot *Rotation) error {
+	if !rot.Verify() {
+		return fmt.Errorf("%w: invalid signature", ErrRotation)
+	}
+
+	if _, ok := r.revoked[rot.old]; ok {
+		return fmt.Errorf("%w: rotation from revoked key", ErrAccountRevoked)
+	}
+
+	rots := r.rotations[rot.old]
+	if rots == nil {
+		rots = make(map[Hash256]*Rotation)
+		r.rotations[rot.old] = rots
+	}
+
+	rots[rot.GetHash()] = rot
+	return nil
+}
+
+// rotation returns rotation from given key, nil if key isn't retired
+func (r *Resolver) rotation(pub PubKey) (*Rotation, error) {
+	rots := r.rotations[pub]
+	if len(rots) > 1 {
+		return nil, ErrRotationFork
+	}
+
+	for _, rot := range rots {
+		return rot, nil
+	}
+
+	return nil, nil
+}
+
+// CurrentKey follows rotation chain from given key and returns current key,
+// ErrAccountRevoked is returned if any key in the chain is revoked
+func (r *Resolver) CurrentKey(pub *PubKey) (*PubKey, error) {
+	cur := *pub
+	seen := map[PubKey]bool{cur: true}
+	for {
+		if _, ok := r.revoked[cur]; ok {
+			return nil, ErrAccountRevoked
+		}
+
+		rot, err := r.rotation(cur)
+		if err != nil {
+			return nil, err
+		}
+
+		if rot == nil {
+			return &cur, nil
+		}
+
+		if seen[rot.new] {
+			return nil, fmt.Errorf("%w: rotation cycle", ErrRotationFork)
+		}
+
+		cur = rot.new
+		seen[cur] = true
+	}
+}
+
+// Resolve follows key rotations and returns the latest valid version of
+// the account with the current key. Version is valid if it's linked to its
+// previous version (when the previous version is known), the latest
+// version must be unique.
+func (r *Resolver) Resolve(pub *PubKey) (*Account, error) {
+	pub, err := r.CurrentKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := r.accounts[*pub]
+	var latest []*Account
+	for _, a := range versions {
+		if r.checkLink(versions, a) != nil {
+			continue
+		}
+
+		switch {
+		case len(latest) == 0 || a.seq > latest[0].seq:
+			latest = []*Account{a}
+		case a.seq == latest[0].seq:
+			latest = append(latest, a)
+		}
+	}
+
+	switch len(latest) {
+	case 0:
+		return nil, ErrAccountUnknown
+	case 1:
+		return latest[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %d versions with sequence %d", ErrAccountFork, len(latest), latest[0].seq)
+	}
+}
+
+// Check returns nil, if a is the latest valid version of its account
+func (r *Resolver) Check(a *Account) error {
+	rot, err := r.rotation(a.pub)
+	if err != nil {
+		return err
+	}
+
+	if rot != nil && a.timestamp > rot.timestamp {
+		return ErrKeyRetired
+	}
+
+	latest, err := r.Resolve(&a.pub)
+	if err != nil {
+		return err
+	}
+
+	if !latest.GetHash().Equal(a.GetHash()) {
+		if a.seq <= latest.seq {
+			return ErrAccountStale
+		}
+
+		return ErrAccountUnknown
+	}
+
+	return nil
+}
+
+// checkLink checks that account follows its previous version
+func (r *Resolver) checkLink(versions map[Hash256]*Account, a *Account) error {
+	if a.seq == 0 {
+		if !a.prev.Empty() {
+			return ErrAccountBadChain
+		}
+		return nil
+	}
+
+	prev, ok := versions[a.prev]
+	if !ok {
+		// previous version is unknown, history has a gap
+		return nil
+	}
+
+	if prev.seq+1 != a.seq || prev.timestamp > a.timestamp {
+		return ErrAccountBadChain
+	}
+
+	return r.checkLink(versions, prev)
+}
@@ -0,0 +1,250 @@
+package bhx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAccountHistory(t *testing.T) {
+	acc, err := MakeNewAccount("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v0 := acc.GetAccount()
+	acc.Fields = map[string]string{"email": "a@example.com"}
+	v1, err := acc.UpdateAccount(v0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acc.Fields = map[string]string{"email": "b@example.com"}
+	v2, err := acc.UpdateAccount(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v2.Sequence() != 2 || !v2.Prev().Equal(v1.GetHash()) || !v2.Verify() {
+		t.Fatal("invalid account update")
+	}
+
+	other, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.UpdateAccount(v2, "x", nil); !errors.Is(err, ErrAccountKey) {
+		t.Fatalf("want %v, got %v", ErrAccountKey, err)
+	}
+
+	r := NewResolver()
+	for _, a := range []*Account{v1, v2, v0} {
+		if _, err := r.Add(a.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	latest, err := r.Resolve(acc.Keys.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest.Get("email") != "b@example.com" {
+		t.Fatalf("unexpected latest version %d", latest.Sequence())
+	}
+
+	if err := r.Check(v1); !errors.Is(err, ErrAccountStale) {
+		t.Fatalf("want %v, got %v", ErrAccountStale, err)
+	}
+
+	// forged version is rejected
+	forged := *v2
+	forged.seq = 5
+	if err := r.AddAccount(&forged); !errors.Is(err, ErrAccountData) {
+		t.Fatalf("want %v, got %v", ErrAccountData, err)
+	}
+
+	// legacy version is rejected, its signature doesn't cover the name
+	legacy := &Account{pub: acc.Keys.pub, name: acc.Name, fields: map[string]string{"email": "d@example.com"}}
+	hash := legacy.GetHash()
+	legacy.sign = *acc.Keys.Sign(hash[:])
+	legacy.name = "mallory"
+	if _, err := r.Add(legacy.Bytes()); !errors.Is(err, ErrAccountVersion) {
+		t.Fatalf("want %v, got %v", ErrAccountVersion, err)
+	}
+
+	// conflicting versions with same sequence
+	acc.Fields = map[string]string{"email": "c@example.com"}
+	fork, err := acc.UpdateAccount(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.AddAccount(fork)
+	if _, err := r.Resolve(acc.Keys.PublicKey()); !errors.Is(err, ErrAccountFork) {
+		t.Fatalf("want %v, got %v", ErrAccountFork, err)
+	}
+
+	rev, err := new(Revocation).SetBytes(acc.Keys.Revoke("key lost").Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.AddRevocation(rev); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Resolve(acc.Keys.PublicKey()); !errors.Is(err, ErrAccountRevoked) {
+		t.Fatalf("want %v, got %v", ErrAccountRevoked, err)
+	}
+
+	rev.reason = "changed"
+	if err := r.AddRevocation(rev); !errors.Is(err, ErrRevocation) {
+		t.Fatalf("want %v, got %v", ErrRevocation, err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	acc, err := MakeNewAccount("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origKey := acc.Keys.PublicKey()
+	oldKeys := acc.Keys
+	v0 := acc.GetAccount()
+
+	rot, err := acc.Rotate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rot, err = new(Rotation).SetBytes(rot.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v1 := acc.GetAccount()
+	r := NewResolver()
+	for _, a := range []*Account{v0, v1} {
+		if err := r.AddAccount(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.AddRotation(rot); err != nil {
+		t.Fatal(err)
+	}
+
+	cur, err := r.CurrentKey(origKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cur.Equal(acc.Keys.PublicKey()) {
+		t.Fatal("rotation is not followed")
+	}
+
+	latest, err := r.Resolve(origKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !latest.GetHash().Equal(v1.GetHash()) {
+		t.Fatal("unexpected resolved account")
+	}
+
+	// retired key can't publish new versions
+	late := oldKeys.GetAccount("tester", nil)
+	late.timestamp = rot.Timestamp() + 1
+	hash := late.GetHash()
+	late.sign = *oldKeys.Sign(hash[:])
+	if err := r.Check(late); !errors.Is(err, ErrKeyRetired) {
+		t.Fatalf("want %v, got %v", ErrKeyRetired, err)
+	}
+
+	if err := r.Check(v0); !errors.Is(err, ErrAccountStale) {
+		t.Fatalf("want %v, got %v", ErrAccountStale, err)
+	}
+
+	// rotation must be signed by both keys
+	thief, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged := oldKeys.RotateTo(thief)
+	forged.newSign = forged.oldSign
+	if err := r.AddRotation(forged); !errors.Is(err, ErrRotation) {
+		t.Fatalf("want %v, got %v", ErrRotation, err)
+	}
+
+	// conflicting hand-off from the same key
+	if err := r.AddRotation(oldKeys.RotateTo(thief)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Resolve(origKey); !errors.Is(err, ErrRotationFork) {
+		t.Fatalf("want %v, got %v", ErrRotationFork, err)
+	}
+}
+
+func TestRevokeThenRotate(t *testing.T) {
+	keys, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thief, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewResolver()
+	for _, k := range []*Keypair{keys, next, thief} {
+		if err := r.AddAccount(k.GetAccount("tester", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// honest rotation, then intermediate key is revoked
+	if err := r.AddRotation(keys.RotateTo(next)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.AddRevocation(next.Revoke("key lost")); err != nil {
+		t.Fatal(err)
+	}
+
+	// revoked key is compromised and hands account off to thief
+	if err := r.AddRotation(next.RotateTo(thief)); !errors.Is(err, ErrAccountRevoked) {
+		t.Fatalf("want %v, got %v", ErrAccountRevoked, err)
+	}
+
+	// rotation added before revocation is known
+	r = NewResolver()
+	if err := r.AddAccount(thief.GetAccount("tester", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rot := range []*Rotation{keys.RotateTo(next), next.RotateTo(thief)} {
+		if err := r.AddRotation(rot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.AddRevocation(next.Revoke("key lost")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range []*Keypair{keys, next} {
+		if _, err := r.Resolve(k.PublicKey()); !errors.Is(err, ErrAccountRevoked) {
+			t.Fatalf("want %v, got %v", ErrAccountRevoked, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package bhx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Encrypted keypair envelope:
+//
+//	magic (4) | version (1) | kdf (1) | time (4) | memory (4) | threads (1) |
+//	salt (16) | nonce (12) | AES-256-GCM ciphertext
+//
+// The whole header is authenticated as GCM additional data.
+// Legacy envelopes (version 0) are nonce (12) | ciphertext and use
+// Sha256H(Sha256H(passw)) as the key.
+
+// Key envelope constants
+const (
+	KeyEncVersionLegacy = 0
+	KeyEncVersion1      = 1
+
+	KDFArgon2id = 1
+
+	keyEncSaltLen   = 16
+	keyEncNonceLen  = 12
+	keyEncHeaderLen = 4 + 1 + 1 + 4 + 4 + 1 + keyEncSaltLen
+	keyEncLegacyLen = keyEncNonceLen + 96 + 16
+
+	maxKDFTime    = 64
+	maxKDFMemory  = 1 << 20 // 1 GiB in KiB, header is read before authentication
+	maxKDFThreads = 64
+)
+
+var keyEncMagic = []byte("bhxk")
+
+// Key envelope errors
+var (
+	ErrInvalidKeyEnvelope = errors.New("invalid encrypted key envelope")
+	ErrUnsupportedKeyEnc  = errors.New("unsupported encrypted key version")
+	ErrUnsupportedKDF     = errors.New("unsupported key derivation function")
+	ErrInvalidKDFParams   = errors.New("invalid key derivation parameters")
+)
+
+// KDFParams is Argon2id password hashing parameters
+type KDFParams struct {
+	Time    uint32 // iterations
+	Memory  uint32 // memory in KiB
+	Threads uint8
+}
+
+// DefaultKDFParams used by Keypair.GetEncrypted
+var DefaultKDFParams = KDFParams{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+func (p KDFParams) valid() bool {
+	return p.Time > 0 && p.Time <= maxKDFTime &&
+		p.Memory >= 8*uint32(p.Threads) && p.Memory <= maxKDFMemory &&
+		p.Threads > 0 && p.Threads <= maxKDFThreads
+}
+
+func (p KDFParams) deriveKey(passw string, salt []byte) []byte {
+	return argon2.IDKey([]byte(passw), salt, p.Time, p.Memory, p.Threads, 32)
+}
+
+// KeyEncVersion returns version of encrypted keypair envelope
+func KeyEncVersion(input []byte) (int, error) {
+	if len(input) == keyEncLegacyLen {
+		return KeyEncVersionLegacy, nil
+	}
+
+	if len(input) < keyEncHeaderLen+keyEncNonceLen || !bytes.HasPrefix(input, keyEncMagic) {
+		return 0, ErrInvalidKeyEnvelope
+	}
+
+	return int(input[4]), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func sealKeyEnvelope(plain []byte, passw string, p KDFParams) ([]byte, error) {
+	if !p.valid() {
+		return nil, ErrInvalidKDFParams
+	}
+
+	out := make([]byte, keyEncHeaderLen+keyEncNonceLen, keyEncHeaderLen+keyEncNonceLen+len(plain)+16)
+	copy(out, keyEncMagic)
+	out[4] = KeyEncVersion1
+	out[5] = KDFArgon2id
+	PutUint32Le(out[6:10], p.Time)
+	PutUint32Le(out[10:14], p.Memory)
+	out[14] = p.Threads
+
+	salt := out[15:keyEncHeaderLen]
+	nonce := out[keyEncHeaderLen:]
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(p.deriveKey(passw, salt))
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(out, nonce, plain, out[:keyEncHeaderLen]), nil
+}
+
+func openKeyEnvelope(input []byte, passw string) ([]byte, error) {
+	ver, err := KeyEncVersion(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ver {
+	case KeyEncVersionLegacy:
+		return openLegacyKeyEnvelope(input, passw)
+	case KeyEncVersion1:
+	default:
+		return nil, ErrUnsupportedKeyEnc
+	}
+
+	if input[5] != KDFArgon2id {
+		return nil, ErrUnsupportedKDF
+	}
+
+	p := KDFParams{
+		Time:    Uint32Le(input[6:10]),
+		Memory:  Uint32Le(input[10:14]),
+		Threads: input[14],
+	}
+
+	if !p.valid() {
+		return nil, ErrInvalidKDFParams
+	}
+
+	gcm, err := newGCM(p.deriveKey(passw, input[15:keyEncHeaderLen]))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := input[keyEncHeaderLen : keyEncHeaderLen+keyEncNonceLen]
+	return gcm.Open(nil, nonce, input[keyEncHeaderLen+keyEncNonceLen:], input[:keyEncHeaderLen])
+}
+
+func openLegacyKeyEnvelope(input []byte, passw string) ([]byte, error) {
+	key := Sha256H(Sha256H([]byte(passw)).Bytes())
+	gcm, err := newGCM(key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	// 0-12 byte is nonce, 12-... is ciphertext
+	return gcm.Open(nil, input[:keyEncNonceLen], input[keyEncNonceLen:], nil)
+}
@@ -0,0 +1,89 @@
+package bhx
+
+// Merkle tree over Hash256 leaves. Inner node is Sha256H(0x01 || left || right),
+// the last node of odd-sized level is promoted to the next level unchanged.
+
+var merkleNodePrefix = []byte{0x01}
+
+func merkleNode(left, right Hash256) Hash256 {
+	return Sha256H(merkleNodePrefix, left[:], right[:])
+}
+
+// merkleLevel computes next tree level in place
+func merkleLevel(level []Hash256) []Hash256 {
+	next := level[:0]
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+
+		next = append(next, merkleNode(level[i], level[i+1]))
+	}
+
+	return next
+}
+
+// MerkleRoot returns root of merkle tree with given leaves
+// (zero hash for empty tree)
+func MerkleRoot(leaves []Hash256) Hash256 {
+	if len(leaves) == 0 {
+		return Hash256{}
+	}
+
+	level := append([]Hash256(nil), leaves...)
+	for len(level) > 1 {
+		level = merkleLevel(level)
+	}
+
+	return level[0]
+}
+
+// MerkleProof returns sibling hashes from leaf at index to the root
+func MerkleProof(leaves []Hash256, index int) []Hash256 {
+	if index < 0 || index >= len(leaves) {
+		return nil
+	}
+
+	path := []Hash256{}
+	level := append([]Hash256(nil), leaves...)
+	for len(level) > 1 {
+		if sib := index ^ 1; sib < len(level) {
+			path = append(path, level[sib])
+		}
+
+		level = merkleLevel(level)
+		index /= 2
+	}
+
+	return path
+}
+
+// MerkleProofRoot computes root from leaf, its index, leaves count and
+// proof path, ok is false if path doesn't fit the tree shape
+func MerkleProofRoot(leaf Hash256, index, count int, path []Hash256) (root Hash256, ok bool) {
+	if index < 0 || index >= count {
+		return Hash256{}, false
+	}
+
+	root = leaf
+	for n := count; n > 1; n = (n + 1) / 2 {
+		if sib := index ^ 1; sib < n {
+			if len(path) == 0 {
+				return Hash256{}, false
+			}
+
+			if index&1 == 0 {
+				root = merkleNode(root, path[0])
+			} else {
+				root = merkleNode(path[0], root)
+			}
+
+			path = path[1:]
+		}
+
+		index /= 2
+	}
+
+	return root, len(path) == 0
+}
@@ -0,0 +1,20 @@
+package bhx
+
+import "testing"
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n < 20; n++ {
+		leaves := make([]Hash256, n)
+		for i := range leaves {
+			leaves[i] = Sha256H([]byte{byte(i)})
+		}
+
+		root := MerkleRoot(leaves)
+		for i := range leaves {
+			got, ok := MerkleProofRoot(leaves[i], i, n, MerkleProof(leaves, i))
+			if !ok || !got.Equal(root) {
+				t.Fatalf("n=%d i=%d: proof verification failed", n, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package bhx
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// BIP-39 mnemonic phrases. Keypair phrase encodes 32-byte ed25519 seed
+// as 24 words, so it's restored with PrivKeyFromSeed.
+
+// Mnemonic errors
+var (
+	ErrMnemonicEntropy  = errors.New("invalid mnemonic entropy length")
+	ErrMnemonicLength   = errors.New("invalid mnemonic words count")
+	ErrMnemonicWord     = errors.New("unknown mnemonic word")
+	ErrMnemonicChecksum = errors.New("invalid mnemonic checksum")
+)
+
+var bip39Index = func() map[string]int {
+	m := make(map[string]int, len(bip39English))
+	for i, w := range bip39English {
+		m[w] = i
+	}
+	return m
+}()
+
+// NewMnemonic encodes entropy (16, 20, 24, 28 or 32 bytes) to BIP-39
+// English phrase
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("%w: %d", ErrMnemonicEntropy, len(entropy))
+	}
+
+	// entropy bits followed by len/4 checksum bits, 11 bits per word
+	sum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), sum[0])
+	count := (len(entropy)*8 + len(entropy)/4) / 11
+	words := make([]string, count)
+	for i := range words {
+		idx := 0
+		for b := i * 11; b < (i+1)*11; b++ {
+			idx = idx<<1 | int(data[b/8]>>(7-uint(b%8))&1)
+		}
+
+		words[i] = bip39English[idx]
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// ParseMnemonic validates BIP-39 English phrase and returns its entropy
+func ParseMnemonic(phrase string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrMnemonicLength, len(words))
+	}
+
+	bits := len(words) * 11
+	data := make([]byte, (bits+7)/8)
+	for i, w := range words {
+		idx, ok := bip39Index[w]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q at position %d%s", ErrMnemonicWord, w, i+1, suggestWord(w))
+		}
+
+		for j := 0; j < 11; j++ {
+			if idx>>(10-uint(j))&1 == 1 {
+				b := i*11 + j
+				data[b/8] |= 1 << (7 - uint(b%8))
+			}
+		}
+	}
+
+	csBits := bits / 33
+	entropy := data[:(bits-csBits)/8]
+	sum := sha256.Sum256(entropy)
+	mask := byte(0xff) << (8 - uint(csBits))
+	if data[len(entropy)]&mask != sum[0]&mask {
+		return nil, ErrMnemonicChecksum
+	}
+
+	return append([]byte{}, entropy...), nil
+}
+
+// suggestWord returns hint with the closest wordlist word by prefix
+func suggestWord(w string) string {
+	i := sort.SearchStrings(bip39English, w)
+	if i == len(bip39English) {
+		i--
+	}
+
+	if len(w) < 3 || !strings.HasPrefix(bip39English[i], w[:3]) {
+		return ""
+	}
+
+	return fmt.Sprintf(" (did you mean %q?)", bip39English[i])
+}
+
+// MnemonicSeed returns BIP-39 seed for phrase and passphrase, it can be
+// used with DeriveKeypair. Passphrase must be NFKD-normalized.
+func MnemonicSeed(phrase, passphrase string) ([]byte, error) {
+	if _, err := ParseMnemonic(phrase); err != nil {
+		return nil, err
+	}
+
+	norm := strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+	return pbkdf2.Key([]byte(norm), []byte("mnemonic"+passphrase), 2048, 64, sha512.New), nil
+}
+
+// Mnemonic returns 24-word phrase for keypair seed
+func (k *Keypair) Mnemonic() (string, error) {
+	return NewMnemonic(SeedOf(&k.priv))
+}
+
+// KeypairFromMnemonic restores keypair from phrase returned by
+// Keypair.Mnemonic
+func KeypairFromMnemonic(phrase string) (*Keypair, error) {
+	seed, err := ParseMnemonic(phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(seed) != PubKeyLen {
+		return nil, fmt.Errorf("%w: keypair needs 24 words", ErrMnemonicLength)
+	}
+
+	priv := PrivKeyFromSeed(seed)
+	return &Keypair{
+		pub:  *PubKeyOf(priv),
+		priv: *priv,
+	}, nil
+}
+
+// Mnemonic returns paper backup phrase for account keys
+func (a *MyAccount) Mnemonic() (string, error) {
+	return a.Keys.Mnemonic()
+}
+
+// RestoreAccount returns account with keys restored from phrase
+func RestoreAccount(name, phrase string) (*MyAccount, error) {
+	keys, err := KeypairFromMnemonic(phrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MyAccount{
+		Fields: make(map[string]string),
+		Keys:   keys,
+		Name:   name,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBIP39Wordlist(t *testing.T) {
+	if len(bip39English) != 2048 || !sort.StringsAreSorted(bip39English) {
+		t.Fatal("invalid wordlist")
+	}
+
+	// crc32 of the original english.txt
+	sum := crc32.ChecksumIEEE([]byte(strings.Join(bip39English, "\n") + "\n"))
+	if sum != 0xc1dbd296 {
+		t.Fatalf("wordlist checksum mismatch: %x", sum)
+	}
+}
+
+func TestMnemonicVectors(t *testing.T) {
+	vectors := []struct {
+		entropy string
+		phrase  string
+		seed    string // with "TREZOR" passphrase
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+			"",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"",
+		},
+	}
+
+	for _, v := range vectors {
+		phrase, err := NewMnemonic(HexDec(v.entropy))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if phrase != v.phrase {
+			t.Fatalf("%s: phrase mismatch: %s", v.entropy, phrase)
+		}
+
+		entropy, err := ParseMnemonic(strings.ToUpper(phrase))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if HexEnc(entropy) != v.entropy {
+			t.Fatalf("%s: entropy mismatch", v.entropy)
+		}
+
+		if v.seed == "" {
+			continue
+		}
+
+		seed, err := MnemonicSeed(phrase, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if HexEnc(seed) != v.seed {
+			t.Fatalf("%s: seed mismatch", v.entropy)
+		}
+	}
+}
+
+func TestKeypairMnemonic(t *testing.T) {
+	acc, err := MakeNewAccount("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phrase, err := acc.Mnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreAccount("tester", phrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(restored.Keys.Bytes(), acc.Keys.Bytes()) {
+		t.Fatal("restored keypair mismatch")
+	}
+
+	words := strings.Fields(phrase)
+	bad := append([]string{}, words...)
+	bad[5] = "abandonx"
+	if _, err := ParseMnemonic(strings.Join(bad, " ")); !errors.Is(err, ErrMnemonicWord) || !strings.Contains(err.Error(), "position 6") {
+		t.Fatalf("want %v, got %v", ErrMnemonicWord, err)
+	}
+
+	// valid last word is "about"
+	if _, err := ParseMnemonic(strings.Repeat("abandon ", 12)); !errors.Is(err, ErrMnemonicChecksum) {
+		t.Fatalf("want %v, got %v", ErrMnemonicChecksum, err)
+	}
+
+	short := strings.Repeat("abandon ", 11) + "about"
+	if _, err := KeypairFromMnemonic(short); !errors.Is(err, ErrMnemonicLength) {
+		t.Fatalf("want %v, got %v", ErrMnemonicLength, err)
+	}
+}
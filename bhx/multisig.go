@@ -0,0 +1,230 @@
+package bhx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MultiSig is document hash with signatures of several keys. Every key
+// signs Sha256H("bhx-multisig" || hash).
+type MultiSig struct {
+	hash Hash256
+	sigs map[PubKey]SigData
+}
+
+// MultiSigPolicy requires Threshold valid signatures of Signers keys
+type MultiSigPolicy struct {
+	Threshold int
+	Signers   []PubKey
+}
+
+// MultiSigStatus is result of multisig verification
+type MultiSigStatus struct {
+	Present []PubKey // policy signers with valid signature
+	Missing []PubKey // policy signers without valid signature
+	Invalid []PubKey // keys with invalid signature
+	Unknown []PubKey // valid signatures of keys not in policy
+}
+
+// MultiSig constants
+const (
+	MultiSigHeaderLen = 32 + 2
+	MultiSigEntryLen  = PubKeyLen + SignSize
+	MultiSigMaxSigs   = 1<<16 - 1
+)
+
+var multiSigPrefix = []byte("bhx-multisig")
+
+// MultiSig errors
+var (
+	ErrMultiSig          = errors.New("invalid multisig data")
+	ErrMultiSigDuplicate = errors.New("duplicate multisig signer")
+	ErrMultiSigSignature = errors.New("invalid multisig signature")
+	ErrMultiSigPolicy    = errors.New("invalid multisig policy")
+	ErrMultiSigThreshold = errors.New("not enough multisig signatures")
+)
+
+// NewMultiSig returns multisig without signatures
+func NewMultiSig(hash Hash256) *MultiSig {
+	return &MultiSig{hash: hash, sigs: make(map[PubKey]SigData)}
+}
+
+// NewFileMultiSig returns multisig for file SHA-256 hash
+func NewFileMultiSig(path string) (*MultiSig, error) {
+	hash, err := FileSha256(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMultiSig(hash), nil
+}
+
+// Hash returns signed document hash
+func (m *MultiSig) Hash() Hash256 { return m.hash }
+
+func (m *MultiSig) message() []byte {
+	hash := Sha256H(multiSigPrefix, m.hash[:])
+	return hash[:]
+}
+
+// Sign adds keypair signature
+func (m *MultiSig) Sign(k *Keypair) error {
+	return m.AddSignature(&k.pub, k.Sign(m.message()))
+}
+
+// AddSignature adds valid signature of new signer
+func (m *MultiSig) AddSignature(pub *PubKey, sig *SigData) error {
+	if _, ok := m.sigs[*pub]; ok {
+		return fmt.Errorf("%w: %s", ErrMultiSigDuplicate, pub)
+	}
+
+	if len(m.sigs) == MultiSigMaxSigs {
+		return fmt.Errorf("%w: too many signatures", ErrMultiSig)
+	}
+
+	if !Verify(pub, m.message(), sig) {
+		return fmt.Errorf("%w: %s", ErrMultiSigSignature, pub)
+	}
+
+	m.sigs[*pub] = *sig
+	return nil
+}
+
+// Signers returns sorted keys of all signatures
+func (m *MultiSig) Signers() []PubKey {
+	keys := make([]PubKey, 0, len(m.sigs))
+	for k := range m.sigs {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+
+	return keys
+}
+
+// Verify checks signatures against policy, status is returned even if
+// threshold isn't reached
+func (m *MultiSig) Verify(p *MultiSigPolicy) (*MultiSigStatus, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	signers := m.Signers()
+	msg := m.message()
+	entries := make([]BatchEntry, len(signers))
+	for i := range signers {
+		sig := m.sigs[signers[i]]
+		entries[i] = BatchEntry{Pub: &signers[i], Msg: msg, Sig: &sig}
+	}
+
+	valid := make(map[PubKey]bool, len(signers))
+	for _, k := range signers {
+		valid[k] = true
+	}
+
+	st := new(MultiSigStatus)
+	for _, i := range VerifyBatch(entries) {
+		valid[signers[i]] = false
+		st.Invalid = append(st.Invalid, signers[i])
+	}
+
+	inPolicy := make(map[PubKey]bool, len(p.Signers))
+	for _, k := range p.Signers {
+		inPolicy[k] = true
+		if valid[k] {
+			st.Present = append(st.Present, k)
+		} else {
+			st.Missing = append(st.Missing, k)
+		}
+	}
+
+	for _, k := range signers {
+		if valid[k] && !inPolicy[k] {
+			st.Unknown = append(st.Unknown, k)
+		}
+	}
+
+	if len(st.Present) < p.Threshold {
+		return st, fmt.Errorf("%w: %d of %d", ErrMultiSigThreshold, len(st.Present), p.Threshold)
+	}
+
+	return st, nil
+}
+
+// Validate checks threshold and signers uniqueness
+func (p *MultiSigPolicy) Validate() error {
+	if p.Threshold < 1 || p.Threshold > len(p.Signers) {
+		return fmt.Errorf("%w: threshold %d of %d", ErrMultiSigPolicy, p.Threshold, len(p.Signers))
+	}
+
+	seen := make(map[PubKey]bool, len(p.Signers))
+	for _, k := range p.Signers {
+		if seen[k] {
+			return fmt.Errorf("%w: %s", ErrMultiSigDuplicate, &k)
+		}
+
+		seen[k] = true
+	}
+
+	return nil
+}
+
+// Bytes returns binary multisig data, entries are sorted by key:
+//
+//	hash (32) | count (2, LE) | count * (pub (32) | signature (64))
+func (m *MultiSig) Bytes() []byte {
+	signers := m.Signers()
+	data := make([]byte, MultiSigHeaderLen, MultiSigHeaderLen+len(signers)*MultiSigEntryLen)
+	copy(data, m.hash[:])
+	PutUint16Le(data[32:], uint16(len(signers)))
+	for _, k := range signers {
+		sig := m.sigs[k]
+		data = append(data, k[:]...)
+		data = append(data, sig[:]...)
+	}
+
+	return data
+}
+
+// SetBytes decode raw multisig, signatures are checked by Verify
+func (m *MultiSig) SetBytes(b []byte) (*MultiSig, error) {
+	if len(b) < MultiSigHeaderLen {
+		return nil, fmt.Errorf("%w: size %d < %d", ErrMultiSig, len(b), MultiSigHeaderLen)
+	}
+
+	count := int(Uint16Le(b[32:]))
+	if len(b) != MultiSigHeaderLen+count*MultiSigEntryLen {
+		return nil, fmt.Errorf("%w: size %d for %d signatures", ErrMultiSig, len(b), count)
+	}
+
+	sigs := make(map[PubKey]SigData, count)
+	var prevKey []byte
+	for i := 0; i < count; i++ {
+		e := b[MultiSigHeaderLen+i*MultiSigEntryLen:]
+		var (
+			pub PubKey
+			sig SigData
+		)
+
+		copy(pub[:], e[:PubKeyLen])
+		copy(sig[:], e[PubKeyLen:MultiSigEntryLen])
+		if _, ok := sigs[pub]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrMultiSigDuplicate, &pub)
+		}
+
+		if prevKey != nil && bytes.Compare(prevKey, pub[:]) > 0 {
+			return nil, fmt.Errorf("%w: signatures are not sorted", ErrMultiSig)
+		}
+
+		prevKey = e[:PubKeyLen]
+		sigs[pub] = sig
+	}
+
+	m.hash.SetBytes(b[:32])
+	m.sigs = sigs
+	return m, nil
+}
@@ -0,0 +1,102 @@
+package bhx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiSig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "release.tar")
+	if err := os.WriteFile(path, []byte("release artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewFileMultiSig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := make([]*Keypair, 4)
+	for i := range keys {
+		keys[i], _ = NewKeypair()
+	}
+
+	policy := &MultiSigPolicy{
+		Threshold: 2,
+		Signers:   []PubKey{keys[0].pub, keys[1].pub, keys[2].pub},
+	}
+
+	for _, k := range []*Keypair{keys[0], keys[3]} {
+		if err := m.Sign(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Sign(keys[0]); !errors.Is(err, ErrMultiSigDuplicate) {
+		t.Fatalf("want %v, got %v", ErrMultiSigDuplicate, err)
+	}
+
+	st, err := m.Verify(policy)
+	if !errors.Is(err, ErrMultiSigThreshold) {
+		t.Fatalf("want %v, got %v", ErrMultiSigThreshold, err)
+	}
+
+	if len(st.Present) != 1 || len(st.Missing) != 2 || len(st.Unknown) != 1 || st.Unknown[0] != keys[3].pub {
+		t.Fatalf("unexpected status %+v", st)
+	}
+
+	if err := m.Sign(keys[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	// decoded signatures are checked by Verify
+	data := m.Bytes()
+	m2, err := new(MultiSig).SetBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st, err = m2.Verify(policy); err != nil || len(st.Present) != 2 || st.Missing[0] != keys[1].pub {
+		t.Fatalf("unexpected status %+v: %v", st, err)
+	}
+
+	for i, pub := range m.Signers() {
+		if pub == keys[0].pub {
+			data[MultiSigHeaderLen+(i+1)*MultiSigEntryLen-1] ^= 1
+		}
+	}
+
+	if m2, err = new(MultiSig).SetBytes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if st, err = m2.Verify(policy); !errors.Is(err, ErrMultiSigThreshold) || len(st.Invalid) != 1 {
+		t.Fatalf("tampered signature accepted: %+v: %v", st, err)
+	}
+
+	// duplicate entry
+	dup := append(m.Bytes()[:MultiSigHeaderLen+MultiSigEntryLen], m.Bytes()[MultiSigHeaderLen:MultiSigHeaderLen+MultiSigEntryLen]...)
+	PutUint16Le(dup[32:], 2)
+	if _, err := new(MultiSig).SetBytes(dup); !errors.Is(err, ErrMultiSigDuplicate) {
+		t.Fatalf("want %v, got %v", ErrMultiSigDuplicate, err)
+	}
+}
+
+func TestMultiSigPolicy(t *testing.T) {
+	k, _ := NewKeypair()
+	for _, p := range []*MultiSigPolicy{
+		{Threshold: 0, Signers: []PubKey{k.pub}},
+		{Threshold: 2, Signers: []PubKey{k.pub}},
+	} {
+		if err := p.Validate(); !errors.Is(err, ErrMultiSigPolicy) {
+			t.Fatalf("want %v, got %v", ErrMultiSigPolicy, err)
+		}
+	}
+
+	p := &MultiSigPolicy{Threshold: 1, Signers: []PubKey{k.pub, k.pub}}
+	if err := p.Validate(); !errors.Is(err, ErrMultiSigDuplicate) {
+		t.Fatalf("want %v, got %v", ErrMultiSigDuplicate, err)
+	}
+}
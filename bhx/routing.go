@@ -0,0 +1,210 @@
+package bhx
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kademlia routing table. Node ID is PubKey.GetHash(), node with i-bit
+// common ID prefix with the table owner goes to i-th k-bucket. Bucket
+// nodes are ordered from least to most recently seen.
+
+// RoutingBucketSize is default k-bucket size
+const RoutingBucketSize = 20
+
+// RoutingNode is routing table entry
+type RoutingNode struct {
+	ID       Hash256
+	Key      PubKey
+	Addr     string
+	LastSeen time.Time
+}
+
+// NewRoutingNode returns node with ID derived from its key
+func NewRoutingNode(key *PubKey, addr string) RoutingNode {
+	return RoutingNode{ID: key.GetHash(), Key: *key, Addr: addr}
+}
+
+// PingFunc checks, if least recently seen node of full bucket is alive
+type PingFunc func(n RoutingNode) bool
+
+// RoutingTable is Kademlia k-bucket routing table, it's safe for
+// concurrent use
+type RoutingTable struct {
+	self Hash256
+	k    int
+	ping PingFunc
+
+	mu      sync.RWMutex
+	buckets [256][]RoutingNode
+}
+
+// NewRoutingTable returns empty routing table for self ID, k is bucket
+// size (0 - RoutingBucketSize). ping is called without table lock, when
+// new node doesn't fit to the bucket: if least recently seen node is
+// alive, new node is dropped, otherwise it replaces the old one. Nil ping
+// keeps old nodes.
+func NewRoutingTable(self Hash256, k int, ping PingFunc) *RoutingTable {
+	if k <= 0 {
+		k = RoutingBucketSize
+	}
+
+	return &RoutingTable{self: self, k: k, ping: ping}
+}
+
+// Self returns table owner ID
+func (t *RoutingTable) Self() Hash256 { return t.self }
+
+// bucketIndex returns bucket index for ID, -1 for self ID
+func (t *RoutingTable) bucketIndex(id Hash256) int {
+	return t.self.PrefixLen(id)
+}
+
+// Insert adds node or marks it as seen, returns false if node isn't in
+// the table after the call
+func (t *RoutingTable) Insert(n RoutingNode) bool {
+	i := t.bucketIndex(n.ID)
+	if i < 0 {
+		return false
+	}
+
+	if n.LastSeen.IsZero() {
+		n.LastSeen = time.Now()
+	}
+
+	t.mu.Lock()
+	if t.touch(i, n) {
+		t.mu.Unlock()
+		return true
+	}
+
+	lru := t.buckets[i][0]
+	t.mu.Unlock()
+
+	alive := t.ping == nil || t.ping(lru)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.buckets[i]
+	pos := indexOfNode(b, lru.ID)
+	if pos >= 0 {
+		if alive {
+			// ping response is a contact too
+			t.buckets[i] = append(append(b[:pos:pos], b[pos+1:]...), b[pos])
+			t.buckets[i][len(b)-1].LastSeen = time.Now()
+		} else {
+			t.buckets[i] = append(b[:pos:pos], b[pos+1:]...)
+		}
+	}
+
+	// bucket could change while pinging
+	return t.touch(i, n)
+}
+
+// touch moves node to the bucket tail or appends it, if bucket isn't
+// full, must be called with lock held
+func (t *RoutingTable) touch(i int, n RoutingNode) bool {
+	b := t.buckets[i]
+	if pos := indexOfNode(b, n.ID); pos >= 0 {
+		b = append(b[:pos], b[pos+1:]...)
+		t.buckets[i] = append(b, n)
+		return true
+	}
+
+	if len(b) < t.k {
+		t.buckets[i] = append(b, n)
+		return true
+	}
+
+	return false
+}
+
+func indexOfNode(b []RoutingNode, id Hash256) int {
+	for i := range b {
+		if b[i].ID == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Remove removes node, returns false if there is no such node
+func (t *RoutingTable) Remove(id Hash256) bool {
+	i := t.bucketIndex(id)
+	if i < 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.buckets[i]
+	pos := indexOfNode(b, id)
+	if pos < 0 {
+		return false
+	}
+
+	t.buckets[i] = append(b[:pos:pos], b[pos+1:]...)
+	return true
+}
+
+// Find returns node by ID
+func (t *RoutingTable) Find(id Hash256) (RoutingNode, bool) {
+	i := t.bucketIndex(id)
+	if i < 0 {
+		return RoutingNode{}, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if pos := indexOfNode(t.buckets[i], id); pos >= 0 {
+		return t.buckets[i][pos], true
+	}
+
+	return RoutingNode{}, false
+}
+
+// Len returns nodes count
+func (t *RoutingTable) Len() (n int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, b := range t.buckets {
+		n += len(b)
+	}
+
+	return
+}
+
+// Bucket returns copy of i-th bucket, from least to most recently seen
+func (t *RoutingTable) Bucket(i int) []RoutingNode {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]RoutingNode(nil), t.buckets[i]...)
+}
+
+// Closest returns up to k nodes closest to target by XOR distance,
+// nil if k <= 0
+func (t *RoutingTable) Closest(target Hash256, k int) []RoutingNode {
+	if k <= 0 {
+		return nil
+	}
+
+	t.mu.RLock()
+	nodes := make([]RoutingNode, 0, t.k)
+	for _, b := range t.buckets {
+		nodes = append(nodes, b...)
+	}
+	t.mu.RUnlock()
+
+	cmp := XorCompare(target)
+	slices.SortFunc(nodes, func(a, b RoutingNode) int {
+		return cmp(a.ID, b.ID)
+	})
+
+	if len(nodes) > k {
+		nodes = nodes[:k]
+	}
+
+	return nodes
+}
@@ -0,0 +1,136 @@
+package bhx
+
+import (
+	"crypto/rand"
+	"sync"
+	"testing"
+)
+
+func randomNode(first byte) RoutingNode {
+	var n RoutingNode
+	rand.Read(n.ID[:])
+	n.ID[0] = first
+	return n
+}
+
+func TestRoutingBucket(t *testing.T) {
+	alive := true
+	var pinged []Hash256
+	rt := NewRoutingTable(Hash256{}, 3, func(n RoutingNode) bool {
+		pinged = append(pinged, n.ID)
+		return alive
+	})
+
+	nodes := make([]RoutingNode, 5)
+	for i := range nodes {
+		// all in bucket 0
+		nodes[i] = randomNode(0x80 | byte(i))
+	}
+
+	for _, n := range nodes[:3] {
+		if !rt.Insert(n) {
+			t.Fatal("node is not inserted")
+		}
+	}
+
+	if rt.Insert(RoutingNode{}) {
+		t.Fatal("self is inserted")
+	}
+
+	// 0 is alive, 3 is dropped
+	if rt.Insert(nodes[3]) || len(pinged) != 1 || pinged[0] != nodes[0].ID {
+		t.Fatal("alive least recently seen node is evicted")
+	}
+
+	b := rt.Bucket(0)
+	if len(b) != 3 || b[0].ID != nodes[1].ID || b[2].ID != nodes[0].ID {
+		t.Fatal("pinged node is not moved to the tail")
+	}
+
+	// 1 is dead, 4 replaces it
+	alive = false
+	if !rt.Insert(nodes[4]) || pinged[1] != nodes[1].ID {
+		t.Fatal("dead node is not replaced")
+	}
+
+	if _, ok := rt.Find(nodes[1].ID); ok {
+		t.Fatal("dead node is found")
+	}
+
+	if !rt.Remove(nodes[4].ID) || rt.Remove(nodes[4].ID) || rt.Len() != 2 {
+		t.Fatal("unexpected remove result")
+	}
+
+	key, _ := NewKeypair()
+	n := NewRoutingNode(key.PublicKey(), "127.0.0.1:7000")
+	if !rt.Insert(n) {
+		t.Fatal("node is not inserted")
+	}
+
+	if got, ok := rt.Find(key.PublicKey().GetHash()); !ok || got.Addr != n.Addr || got.LastSeen.IsZero() {
+		t.Fatal("node is not found by key hash")
+	}
+}
+
+func TestRoutingClosest(t *testing.T) {
+	var self Hash256
+	rand.Read(self[:])
+	rt := NewRoutingTable(self, 0, nil)
+	for i := 0; i < 500; i++ {
+		var n RoutingNode
+		rand.Read(n.ID[:])
+		rt.Insert(n)
+	}
+
+	var target Hash256
+	rand.Read(target[:])
+	closest := rt.Closest(target, 20)
+	if len(closest) != 20 {
+		t.Fatalf("want 20 nodes, got %d", len(closest))
+	}
+
+	for i := 1; i < len(closest); i++ {
+		prev := closest[i-1].ID.Xor(target).ToBigInt()
+		if prev.Cmp(closest[i].ID.Xor(target).ToBigInt()) >= 0 {
+			t.Fatal("nodes are not sorted by XOR distance")
+		}
+	}
+
+	// no other node is closer than the last one
+	last := closest[len(closest)-1].ID.Xor(target).ToBigInt()
+	for _, n := range rt.Closest(target, rt.Len())[20:] {
+		if n.ID.Xor(target).ToBigInt().Cmp(last) < 0 {
+			t.Fatal("closer node is missed")
+		}
+	}
+
+	for _, k := range []int{0, -1} {
+		if nodes := rt.Closest(target, k); len(nodes) != 0 {
+			t.Fatalf("k %d: want no nodes, got %d", k, len(nodes))
+		}
+	}
+}
+
+func TestRoutingConcurrent(t *testing.T) {
+	rt := NewRoutingTable(Hash256{}, 4, func(RoutingNode) bool { return false })
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				n := randomNode(byte(i))
+				rt.Insert(n)
+				rt.Closest(n.ID, 8)
+				rt.Remove(n.ID)
+			}
+		}()
+	}
+
+	wg.Wait()
+	for i := 0; i < 256; i++ {
+		if len(rt.Bucket(i)) > 4 {
+			t.Fatalf("bucket %d exceeds k", i)
+		}
+	}
+}
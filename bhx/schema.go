@@ -0,0 +1,311 @@
+package bhx
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// FieldType is account field value type
+type FieldType uint8
+
+// Field types
+const (
+	FieldString  FieldType = iota // any string
+	FieldInt                      // base 10 int64
+	FieldTime                     // RFC 3339 time
+	FieldEmail                    // bare email address
+	FieldURL                      // absolute URL
+	FieldPubKey                   // hex-encoded PubKey
+	FieldHash256                  // hex-encoded Hash256
+)
+
+var fieldTypeNames = [...]string{"string", "int", "time", "email", "url", "pubkey", "hash256"}
+
+func (t FieldType) String() string {
+	if int(t) < len(fieldTypeNames) {
+		return fieldTypeNames[t]
+	}
+
+	return "FieldType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// Field errors
+var (
+	ErrFieldValue = errors.New("invalid account field value")
+	ErrSchema     = errors.New("account fields do not match schema")
+)
+
+// FieldRule describes single account field
+type FieldRule struct {
+	Type     FieldType
+	Required bool
+	MinLen   int            // min value length in bytes
+	MaxLen   int            // max value length in bytes, 0 - no limit
+	Pattern  *regexp.Regexp // value must match, if set
+}
+
+// Schema describes allowed account fields
+type Schema struct {
+	Fields       map[string]FieldRule
+	MaxFields    int  // 0 - no limit (besides AccountMaxFields)
+	AllowUnknown bool // allow fields which are not in Fields
+}
+
+// Validate checks fields against the schema
+func (s *Schema) Validate(fields map[string]string) error {
+	if s.MaxFields > 0 && len(fields) > s.MaxFields {
+		return fmt.Errorf("%w: %d fields > %d", ErrSchema, len(fields), s.MaxFields)
+	}
+
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+
+	// stable error for the same input
+	sort.Strings(names)
+	for _, name := range names {
+		rule := s.Fields[name]
+		val, ok := fields[name]
+		if !ok {
+			if rule.Required {
+				return fmt.Errorf("%w: field %q is required", ErrSchema, name)
+			}
+			continue
+		}
+
+		if err := rule.check(val); err != nil {
+			return fmt.Errorf("%w: field %q: %v", ErrSchema, name, err)
+		}
+	}
+
+	if !s.AllowUnknown {
+		for _, name := range (&Account{fields: fields}).sortedKeys() {
+			if _, ok := s.Fields[name]; !ok {
+				return fmt.Errorf("%w: unknown field %q", ErrSchema, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r FieldRule) check(val string) error {
+	if len(val) < r.MinLen {
+		return fmt.Errorf("length %d < %d", len(val), r.MinLen)
+	}
+
+	if r.MaxLen > 0 && len(val) > r.MaxLen {
+		return fmt.Errorf("length %d > %d", len(val), r.MaxLen)
+	}
+
+	if r.Pattern != nil && !r.Pattern.MatchString(val) {
+		return fmt.Errorf("value does not match %s", r.Pattern)
+	}
+
+	return parseField(r.Type, val)
+}
+
+func parseField(t FieldType, val string) (err error) {
+	switch t {
+	case FieldString:
+	case FieldInt:
+		_, err = parseInt(val)
+	case FieldTime:
+		_, err = parseTime(val)
+	case FieldEmail:
+		err = parseEmail(val)
+	case FieldURL:
+		_, err = parseURL(val)
+	case FieldPubKey:
+		_, err = parsePubKey(val)
+	case FieldHash256:
+		_, err = parseHash256(val)
+	default:
+		err = fmt.Errorf("unknown type %s", t)
+	}
+
+	return
+}
+
+func parseInt(val string) (int64, error) {
+	i, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, errors.New("not an integer")
+	}
+
+	return i, nil
+}
+
+func parseTime(val string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return time.Time{}, errors.New("not an RFC 3339 time")
+	}
+
+	return t, nil
+}
+
+func parseEmail(val string) error {
+	addr, err := mail.ParseAddress(val)
+	if err != nil || addr.Name != "" || addr.Address != val {
+		return errors.New("not an email address")
+	}
+
+	return nil
+}
+
+func parseURL(val string) (*url.URL, error) {
+	u, err := url.Parse(val)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("not an absolute URL")
+	}
+
+	return u, nil
+}
+
+func parsePubKey(val string) (*PubKey, error) {
+	k, err := ParsePubKey(val)
+	if err != nil {
+		return nil, fmt.Errorf("not a hex public key: %v", err)
+	}
+
+	return k, nil
+}
+
+func parseHash256(val string) (Hash256, error) {
+	h, err := ParseHash256(val)
+	if err != nil {
+		return h, fmt.Errorf("not a hex hash: %v", err)
+	}
+
+	return h, nil
+}
+
+// field returns field value or ErrFieldNotFound
+func (a *Account) field(name string) (string, error) {
+	val, ok := a.fields[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrFieldNotFound, name)
+	}
+
+	return val, nil
+}
+
+func fieldError(name string, err error) error {
+	return fmt.Errorf("%w: field %q: %v", ErrFieldValue, name, err)
+}
+
+// GetInt returns integer field value
+func (a *Account) GetInt(name string) (int64, error) {
+	val, err := a.field(name)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := parseInt(val)
+	if err != nil {
+		return 0, fieldError(name, err)
+	}
+
+	return i, nil
+}
+
+// GetTime returns RFC 3339 time field value
+func (a *Account) GetTime(name string) (time.Time, error) {
+	val, err := a.field(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	t, err := parseTime(val)
+	if err != nil {
+		return time.Time{}, fieldError(name, err)
+	}
+
+	return t, nil
+}
+
+// GetURL returns absolute URL field value
+func (a *Account) GetURL(name string) (*url.URL, error) {
+	val, err := a.field(name)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := parseURL(val)
+	if err != nil {
+		return nil, fieldError(name, err)
+	}
+
+	return u, nil
+}
+
+// GetPubKey returns hex-encoded public key field value
+func (a *Account) GetPubKey(name string) (*PubKey, error) {
+	val, err := a.field(name)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := parsePubKey(val)
+	if err != nil {
+		return nil, fieldError(name, err)
+	}
+
+	return k, nil
+}
+
+// GetHash256 returns hex-encoded hash field value
+func (a *Account) GetHash256(name string) (Hash256, error) {
+	val, err := a.field(name)
+	if err != nil {
+		return Hash256{}, err
+	}
+
+	h, err := parseHash256(val)
+	if err != nil {
+		return Hash256{}, fieldError(name, err)
+	}
+
+	return h, nil
+}
+
+// VerifyWith checks account signature, encoding limits and fields schema
+func (a *Account) VerifyWith(s *Schema) error {
+	if !a.Verify() {
+		return fmt.Errorf("%w: invalid signature", ErrAccountData)
+	}
+
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	return s.Validate(a.fields)
+}
+
+// SignAccount validates fields with schema (may be nil) and returns
+// signed public account
+func (a *MyAccount) SignAccount(s *Schema) (*Account, error) {
+	if s != nil {
+		if err := s.Validate(a.Fields); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := (&Account{name: a.Name, fields: a.Fields}).Validate(); err != nil {
+		return nil, err
+	}
+
+	return a.GetAccount(), nil
+}
@@ -0,0 +1,109 @@
+package bhx
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func testSchema() *Schema {
+	return &Schema{
+		Fields: map[string]FieldRule{
+			"email":   {Type: FieldEmail, Required: true},
+			"site":    {Type: FieldURL},
+			"age":     {Type: FieldInt},
+			"born":    {Type: FieldTime},
+			"device":  {Type: FieldPubKey},
+			"avatar":  {Type: FieldHash256},
+			"country": {Pattern: regexp.MustCompile(`^[A-Z]{2}$`)},
+			"bio":     {MaxLen: 16},
+		},
+		MaxFields: 8,
+	}
+}
+
+func TestSchema(t *testing.T) {
+	acc, err := MakeNewAccount("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	avatar := Sha256H([]byte("avatar"))
+	acc.Fields = map[string]string{
+		"email":   "user@example.com",
+		"site":    "https://example.com/user",
+		"age":     "42",
+		"born":    "1990-01-02T03:04:05Z",
+		"device":  device.PublicKey().String(),
+		"avatar":  avatar.String(),
+		"country": "NL",
+	}
+
+	schema := testSchema()
+	pac, err := acc.SignAccount(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pac.VerifyWith(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if age, err := pac.GetInt("age"); err != nil || age != 42 {
+		t.Fatalf("GetInt: %d, %v", age, err)
+	}
+
+	if born, err := pac.GetTime("born"); err != nil || !born.Equal(time.Date(1990, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("GetTime: %s, %v", born, err)
+	}
+
+	if u, err := pac.GetURL("site"); err != nil || u.Host != "example.com" {
+		t.Fatalf("GetURL: %v, %v", u, err)
+	}
+
+	if k, err := pac.GetPubKey("device"); err != nil || !k.Equal(device.PublicKey()) {
+		t.Fatalf("GetPubKey: %v, %v", k, err)
+	}
+
+	if h, err := pac.GetHash256("avatar"); err != nil || !h.Equal(avatar) {
+		t.Fatalf("GetHash256: %v, %v", h, err)
+	}
+
+	if _, err := pac.GetInt("email"); !errors.Is(err, ErrFieldValue) {
+		t.Fatalf("want %v, got %v", ErrFieldValue, err)
+	}
+
+	if _, err := pac.GetInt("missing"); !errors.Is(err, ErrFieldNotFound) {
+		t.Fatalf("want %v, got %v", ErrFieldNotFound, err)
+	}
+
+	invalid := []map[string]string{
+		{},
+		{"email": "User <user@example.com>"},
+		{"email": "user@example.com", "site": "/relative"},
+		{"email": "user@example.com", "age": "4x"},
+		{"email": "user@example.com", "born": "yesterday"},
+		{"email": "user@example.com", "device": "abcd"},
+		{"email": "user@example.com", "avatar": "zz"},
+		{"email": "user@example.com", "country": "nl"},
+		{"email": "user@example.com", "bio": "too long biography text"},
+		{"email": "user@example.com", "unknown": "1"},
+	}
+
+	for _, fields := range invalid {
+		acc.Fields = fields
+		if _, err := acc.SignAccount(schema); !errors.Is(err, ErrSchema) {
+			t.Fatalf("%v: want %v, got %v", fields, ErrSchema, err)
+		}
+
+		if err := acc.GetAccount().VerifyWith(schema); !errors.Is(err, ErrSchema) {
+			t.Fatalf("%v: want %v, got %v", fields, ErrSchema, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bhx
+
+import (
+	"crypto/rand"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// Sealed boxes are encrypted with ephemeral sender keys, so sender needs no
+// identity. Format is compatible with libsodium crypto_box_seal:
+//
+//	ephemeral pub (32) | box(msg) with nonce blake2b(ephemeral pub || recipient pub)
+
+// SealOverhead is sealed box size overhead
+const SealOverhead = box.AnonymousOverhead
+
+// SealTo encrypts message for recipient box public key
+func SealTo(to *BoxPub, msg []byte) ([]byte, error) {
+	var pub [32]byte
+	copy(pub[:], to[:])
+	return box.SealAnonymous(nil, msg, &pub, rand.Reader)
+}
+
+// SealToAccount encrypts message for account owner
+func SealToAccount(to *Account, msg []byte) ([]byte, error) {
+	pub, err := to.BoxPub()
+	if err != nil {
+		return nil, err
+	}
+
+	return SealTo(pub, msg)
+}
+
+// OpenSealed decrypts sealed box with recipient private key
+func OpenSealed(priv *BoxPriv, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < SealOverhead {
+		return nil, ErrInvalidByteLen
+	}
+
+	pk, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var pub, key [32]byte
+	copy(pub[:], pk)
+	copy(key[:], priv[:])
+	msg, ok := box.OpenAnonymous(nil, ciphertext, &pub, &key)
+	if !ok {
+		return nil, ErrDecrypt
+	}
+
+	return msg, nil
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"fmt"
+	"io"
 )
 
 // Length contants
@@ -25,7 +27,8 @@ func (k *PubKey) String() string {
 	return HexEnc(k[:])
 }
 
-// SetString decodes hex-encoded key
+// SetString decodes hex-encoded key, invalid string gives zero or partial
+// key, use ParsePubKey to check the string
 func (k *PubKey) SetString(str string) *PubKey {
 	copy(k[:], HexDec(str))
 	return k
@@ -39,9 +42,15 @@ func (k *PubKey) GetHash() Hash256 {
 // PrivKey is ed25519 private key type
 type PrivKey [PrivKeyLen]byte
 
-func (k *PrivKey) String() string {
-	return HexEnc(k[:])
-}
+// privKeyRedacted is printed instead of private key
+const privKeyRedacted = "PrivKey(redacted)"
+
+// String returns redacted key, so it never leaks to logs, use MarshalText
+// to encode the key
+func (k *PrivKey) String() string { return privKeyRedacted }
+
+// Format implements fmt.Formatter, key is redacted for every verb
+func (k PrivKey) Format(f fmt.State, verb rune) { io.WriteString(f, privKeyRedacted) }
 
 // SetString decodes hex-encoded key
 func (k *PrivKey) SetString(str string) *PrivKey {
@@ -56,7 +65,8 @@ func (k *SigData) String() string {
 	return HexEnc(k[:])
 }
 
-// SetString decodes hex-encoded key
+// SetString decodes hex-encoded signature, invalid string gives zero or
+// partial signature, use ParseSigData to check the string
 func (k *SigData) SetString(str string) *SigData {
 	copy(k[:], HexDec(str))
 	return k
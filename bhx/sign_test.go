@@ -0,0 +1,105 @@
+package bhx
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	_ fixedType = new(PubKey)
+	_ fixedType = new(PrivKey)
+	_ fixedType = new(SigData)
+
+	_ gob.GobEncoder         = PubKey{}
+	_ gob.GobDecoder         = new(SigData)
+	_ encoding.TextMarshaler = Hash256{}
+	_ sql.Scanner            = new(PrivKey)
+	_ driver.Valuer          = SigData{}
+)
+
+func TestKeyEncoding(t *testing.T) {
+	k, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type keys struct {
+		Pub  PubKey
+		Priv PrivKey
+		Sig  SigData
+		Hash Hash256
+	}
+
+	in := keys{Pub: k.pub, Priv: k.priv, Sig: *k.Sign([]byte("msg")), Hash: k.pub.GetHash()}
+	data, err := json.Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Contains(data, []byte(`"Pub":"`+k.pub.String()+`"`)) {
+		t.Fatalf("public key is not hex string: %s", data)
+	}
+
+	var out keys
+	if err := json.Unmarshal(data, &out); err != nil || out != in {
+		t.Fatalf("json round trip: %v", err)
+	}
+
+	var buf bytes.Buffer
+	out = keys{}
+	if err := gob.NewEncoder(&buf).Encode(&in); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil || out != in {
+		t.Fatalf("gob round trip: %v", err)
+	}
+
+	var pub PubKey
+	err = pub.UnmarshalText([]byte(k.pub.String()[:60]))
+	if !errors.Is(err, ErrInvalidByteLen) {
+		t.Fatalf("want %v, got %v", ErrInvalidByteLen, err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Sig":"xyz"}`), &out); !errors.Is(err, ErrInvalidHex) {
+		t.Fatalf("want %v, got %v", ErrInvalidHex, err)
+	}
+}
+
+func TestPrivKeyRedacted(t *testing.T) {
+	k, err := NewKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := HexEnc(SeedOf(&k.priv))
+	for _, s := range []string{
+		k.priv.String(),
+		fmt.Sprint(k.priv),
+		fmt.Sprintf("%v %+v %#v %x %X %s %q", k.priv, &k.priv, k.priv, k.priv, k.priv, &k.priv, k.priv),
+		fmt.Sprintf("%v %+v %#v %x", k, k, *k, k),
+		fmt.Sprintf("%v", &MyAccount{Keys: k}),
+	} {
+		if strings.Contains(strings.ToLower(s), secret[:16]) {
+			t.Fatalf("private key leaked: %s", s)
+		}
+	}
+
+	if s := fmt.Sprintf("%d", k.priv); s != privKeyRedacted {
+		t.Fatalf("unexpected formatted key %s", s)
+	}
+
+	text, _ := k.priv.MarshalText()
+	var priv PrivKey
+	if err := priv.UnmarshalText(text); err != nil || priv != k.priv {
+		t.Fatalf("private key text round trip: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package bhx
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Encrypted stream format:
+//
+//	version (1) | nonce prefix (16) | chunk 0 | chunk 1 | ... | final chunk
+//
+// Every chunk is StreamChunkSize bytes of plaintext sealed with
+// XChaCha20-Poly1305 (final chunk may be shorter or empty). Chunk nonce is
+// prefix || chunk counter (LE), additional data is the final chunk flag,
+// so reordered, dropped or truncated chunks fail authentication.
+
+// Stream constants
+const (
+	StreamVersion   = 1
+	StreamChunkSize = 64 * 1024
+	StreamOverhead  = chacha20poly1305.Overhead
+
+	streamPrefixLen = BoxNonceLen - 8
+	streamHeaderLen = 1 + streamPrefixLen
+)
+
+// Stream errors
+var (
+	ErrStreamVersion   = errors.New("unsupported stream version")
+	ErrStreamTruncated = errors.New("encrypted stream is truncated")
+	ErrStreamClosed    = errors.New("encrypted stream is closed")
+)
+
+var (
+	streamNotFinal = []byte{0}
+	streamFinal    = []byte{1}
+)
+
+type streamCipher struct {
+	aead    cipher.AEAD
+	nonce   BoxNonce
+	counter uint64
+}
+
+func (s *streamCipher) nextNonce() []byte {
+	if s.counter == ^uint64(0) {
+		panic("bhx: encrypted stream chunk counter overflow")
+	}
+
+	PutUint64Le(s.nonce[streamPrefixLen:], s.counter)
+	s.counter++
+	return s.nonce[:]
+}
+
+func streamAD(final bool) []byte {
+	if final {
+		return streamFinal
+	}
+
+	return streamNotFinal
+}
+
+type encryptWriter struct {
+	streamCipher
+	w      io.Writer
+	buf    []byte
+	out    []byte
+	closed bool
+}
+
+// NewEncryptWriter returns writer that encrypts data to w with
+// XChaCha20-Poly1305 in StreamChunkSize chunks. Close must be called
+// to write the final chunk, w is not closed.
+func NewEncryptWriter(w io.Writer, key BoxSharedKey) (io.WriteCloser, error) {
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	ew := &encryptWriter{
+		w:   w,
+		buf: make([]byte, 0, StreamChunkSize),
+		out: make([]byte, 0, StreamChunkSize+StreamOverhead),
+	}
+
+	ew.aead = aead
+	if _, err := io.ReadFull(rand.Reader, ew.nonce[:streamPrefixLen]); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderLen)
+	header[0] = StreamVersion
+	copy(header[1:], ew.nonce[:streamPrefixLen])
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return ew, nil
+}
+
+func (w *encryptWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, ErrStreamClosed
+	}
+
+	for len(p) > 0 {
+		// chunk is flushed only when more data arrives, because the last
+		// one must be sealed as final in Close
+		if len(w.buf) == StreamChunkSize {
+			if err = w.flush(false); err != nil {
+				return
+			}
+		}
+
+		c := copy(w.buf[len(w.buf):StreamChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+c]
+		p = p[c:]
+		n += c
+	}
+
+	return
+}
+
+func (w *encryptWriter) flush(final bool) error {
+	w.out = w.aead.Seal(w.out[:0], w.nextNonce(), w.buf, streamAD(final))
+	w.buf = w.buf[:0]
+	_, err := w.w.Write(w.out)
+	return err
+}
+
+// Close writes final chunk
+func (w *encryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+
+	w.closed = true
+	return w.flush(true)
+}
+
+type decryptReader struct {
+	streamCipher
+	r    *bufio.Reader
+	in   []byte
+	out  []byte
+	buf  []byte
+	done bool
+	err  error
+}
+
+// NewDecryptReader returns reader that decrypts stream created by
+// NewEncryptWriter. Read returns an error, if stream was modified,
+// reordered or truncated; data is returned only after its chunk is verified.
+func NewDecryptReader(r io.Reader, key BoxSharedKey) (io.Reader, error) {
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			err = ErrStreamTruncated
+		}
+		return nil, err
+	}
+
+	if header[0] != StreamVersion {
+		return nil, ErrStreamVersion
+	}
+
+	dr := &decryptReader{
+		r:   bufio.NewReaderSize(r, StreamChunkSize+StreamOverhead),
+		in:  make([]byte, StreamChunkSize+StreamOverhead),
+		out: make([]byte, 0, StreamChunkSize),
+	}
+
+	dr.aead = aead
+	copy(dr.nonce[:], header[1:])
+	return dr, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if r.done {
+			return 0, io.EOF
+		}
+
+		r.err = r.next()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptReader) next() error {
+	n, err := io.ReadFull(r.r, r.in)
+	switch err {
+	case nil:
+	case io.ErrUnexpectedEOF:
+	case io.EOF:
+		return ErrStreamTruncated
+	default:
+		return err
+	}
+
+	if n < StreamOverhead {
+		return ErrStreamTruncated
+	}
+
+	final := n < len(r.in)
+	if !final {
+		if _, err := r.r.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	nonce := r.nextNonce()
+	buf, err := r.aead.Open(r.out[:0], nonce, r.in[:n], streamAD(final))
+	if err != nil {
+		if final {
+			// valid non-final chunk at the end means that stream is cut
+			if _, err := r.aead.Open(nil, nonce, r.in[:n], streamAD(false)); err == nil {
+				return ErrStreamTruncated
+			}
+		}
+
+		return ErrDecrypt
+	}
+
+	r.buf = buf
+	r.done = final
+	return nil
+}
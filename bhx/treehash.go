@@ -0,0 +1,227 @@
+package bhx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Tree hash splits data to fixed-size chunks, which are hashed in parallel.
+// Chunk hash is alg(0x00 || chunk), chunk hashes are merkle tree leaves
+// (see MerkleRoot) and root commits to tree parameters:
+//
+//	Sha256H(0x03 || multihash(merkle root, alg) || chunk size (8, LE) || size (8, LE))
+//
+// so single chunk is verified with root and its merkle proof.
+
+// TreeHashChunkSize is recommended tree hash chunk size
+const TreeHashChunkSize = 4 << 20
+
+var (
+	treeLeafPrefix = []byte{0x00}
+	treeRootPrefix = []byte{0x03}
+)
+
+// ErrTreeHash is returned for chunk, which doesn't match tree hash
+var ErrTreeHash = errors.New("tree hash mismatch")
+
+// TreeHash is chunked tree hash of data
+type TreeHash struct {
+	Alg       HashAlg
+	ChunkSize int64
+	Size      int64
+	Root      Hash256
+	Chunks    []Hash256
+}
+
+// treeChunkCount returns chunks count for data size
+func treeChunkCount(size, chunkSize int64) int {
+	return int((size + chunkSize - 1) / chunkSize)
+}
+
+// treeRoot returns root for merkle root of chunk hashes
+func treeRoot(alg HashAlg, chunkSize, size int64, merkleRoot Hash256) Hash256 {
+	params := make([]byte, 16)
+	PutUint64Le(params[:8], uint64(chunkSize))
+	PutUint64Le(params[8:], uint64(size))
+	return Sha256H(treeRootPrefix, merkleRoot.Multihash(alg), params)
+}
+
+// TreeHashFile returns tree hash of file, chunkSize must be positive
+// (see TreeHashChunkSize), workers 0 means GOMAXPROCS
+func TreeHashFile(ctx context.Context, alg HashAlg, path string, chunkSize int64, workers int) (*TreeHash, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return TreeHashReaderAt(ctx, alg, fd, fi.Size(), chunkSize, workers)
+}
+
+// TreeHashReaderAt returns tree hash of size bytes from r
+func TreeHashReaderAt(ctx context.Context, alg HashAlg, r io.ReaderAt, size, chunkSize int64, workers int) (*TreeHash, error) {
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if chunkSize <= 0 || size < 0 || workers < 0 {
+		return nil, fmt.Errorf("invalid tree hash parameters: size %d, chunk size %d, workers %d", size, chunkSize, workers)
+	}
+
+	if _, err := NewHasher(alg); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		werr    error
+	)
+
+	t := &TreeHash{
+		Alg:       alg,
+		ChunkSize: chunkSize,
+		Size:      size,
+		Chunks:    make([]Hash256, treeChunkCount(size, chunkSize)),
+	}
+
+	// chunk buffer isn't larger than data
+	bufLen := chunkSize
+	if size < bufLen {
+		bufLen = size
+	}
+
+	indexes := make(chan int)
+	for w := 0; w < workers && w < len(t.Chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, bufLen)
+			for i := range indexes {
+				n := t.chunkLen(i)
+				if got, err := r.ReadAt(buf[:n], int64(i)*chunkSize); int64(got) != n {
+					if err == nil || err == io.EOF {
+						err = io.ErrUnexpectedEOF
+					}
+
+					errOnce.Do(func() { werr = err })
+					cancel()
+					continue
+				}
+
+				t.Chunks[i] = t.chunkHash(buf[:n])
+			}
+		}()
+	}
+
+loop:
+	for i := range t.Chunks {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+
+	close(indexes)
+	wg.Wait()
+	if werr != nil {
+		return nil, werr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	t.Root = treeRoot(alg, chunkSize, size, MerkleRoot(t.Chunks))
+	return t, nil
+}
+
+// chunkLen returns expected chunk length
+func (t *TreeHash) chunkLen(index int) int64 {
+	if rest := t.Size - int64(index)*t.ChunkSize; rest < t.ChunkSize {
+		return rest
+	}
+
+	return t.ChunkSize
+}
+
+func (t *TreeHash) chunkHash(data []byte) Hash256 {
+	h, _ := HashBytes(t.Alg, treeLeafPrefix, data)
+	return h
+}
+
+// Validate checks that chunk hashes match the root
+func (t *TreeHash) Validate() error {
+	if t.ChunkSize <= 0 || t.Size < 0 || len(t.Chunks) != treeChunkCount(t.Size, t.ChunkSize) {
+		return fmt.Errorf("%w: %d chunks for size %d", ErrTreeHash, len(t.Chunks), t.Size)
+	}
+
+	if _, err := NewHasher(t.Alg); err != nil {
+		return err
+	}
+
+	if treeRoot(t.Alg, t.ChunkSize, t.Size, MerkleRoot(t.Chunks)) != t.Root {
+		return fmt.Errorf("%w: chunk hashes don't match root", ErrTreeHash)
+	}
+
+	return nil
+}
+
+// VerifyChunk checks chunk data against chunk hash, chunk hashes must be
+// validated with Validate before
+func (t *TreeHash) VerifyChunk(index int, data []byte) error {
+	if index < 0 || index >= len(t.Chunks) {
+		return fmt.Errorf("%w: chunk %d of %d", ErrTreeHash, index, len(t.Chunks))
+	}
+
+	if int64(len(data)) != t.chunkLen(index) || t.chunkHash(data) != t.Chunks[index] {
+		return fmt.Errorf("%w: chunk %d", ErrTreeHash, index)
+	}
+
+	return nil
+}
+
+// ChunkProof returns merkle proof of chunk hash
+func (t *TreeHash) ChunkProof(index int) []Hash256 {
+	return MerkleProof(t.Chunks, index)
+}
+
+// VerifyTreeChunk checks single chunk with tree parameters, root and
+// chunk proof, without full chunks list
+func VerifyTreeChunk(root Hash256, alg HashAlg, chunkSize, size int64, index int, data []byte, proof []Hash256) error {
+	t := &TreeHash{Alg: alg, ChunkSize: chunkSize, Size: size}
+	if chunkSize <= 0 || size < 0 {
+		return fmt.Errorf("%w: invalid chunk size %d", ErrTreeHash, chunkSize)
+	}
+
+	if _, err := NewHasher(alg); err != nil {
+		return err
+	}
+
+	count := treeChunkCount(size, chunkSize)
+	if index < 0 || index >= count || int64(len(data)) != t.chunkLen(index) {
+		return fmt.Errorf("%w: chunk %d", ErrTreeHash, index)
+	}
+
+	merkleRoot, ok := MerkleProofRoot(t.chunkHash(data), index, count, proof)
+	if !ok || treeRoot(alg, chunkSize, size, merkleRoot) != root {
+		return fmt.Errorf("%w: chunk %d", ErrTreeHash, index)
+	}
+
+	return nil
+}
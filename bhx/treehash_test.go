@@ -0,0 +1,117 @@
+package bhx
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTreeHash(t *testing.T) {
+	data := make([]byte, 10*1024+123)
+	rand.Read(data)
+	path := filepath.Join(t.TempDir(), "artifact")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const chunkSize = 1024
+	th, err := TreeHashFile(context.Background(), HashBLAKE3, path, chunkSize, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(th.Chunks) != 11 {
+		t.Fatalf("want 11 chunks, got %d", len(th.Chunks))
+	}
+
+	// result doesn't depend on workers count
+	th1, err := TreeHashReaderAt(context.Background(), HashBLAKE3, bytes.NewReader(data), int64(len(data)), chunkSize, 1)
+	if err != nil || th1.Root != th.Root {
+		t.Fatalf("single worker root mismatch: %v", err)
+	}
+
+	if err := th.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range th.Chunks {
+		chunk := data[i*chunkSize:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		if err := th.VerifyChunk(i, chunk); err != nil {
+			t.Fatal(err)
+		}
+
+		proof := th.ChunkProof(i)
+		if err := VerifyTreeChunk(th.Root, HashBLAKE3, chunkSize, int64(len(data)), i, chunk, proof); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+	}
+
+	bad := append([]byte{}, data[:chunkSize]...)
+	bad[0] ^= 1
+	if err := th.VerifyChunk(0, bad); !errors.Is(err, ErrTreeHash) {
+		t.Fatalf("want %v, got %v", ErrTreeHash, err)
+	}
+
+	if err := VerifyTreeChunk(th.Root, HashBLAKE3, chunkSize, int64(len(data)), 0, bad, th.ChunkProof(0)); !errors.Is(err, ErrTreeHash) {
+		t.Fatalf("want %v, got %v", ErrTreeHash, err)
+	}
+
+	// root commits to size
+	if err := VerifyTreeChunk(th.Root, HashBLAKE3, chunkSize, int64(len(data))+1, 0, data[:chunkSize], th.ChunkProof(0)); !errors.Is(err, ErrTreeHash) {
+		t.Fatalf("want %v, got %v", ErrTreeHash, err)
+	}
+
+	th.Chunks[3][0] ^= 1
+	if err := th.Validate(); !errors.Is(err, ErrTreeHash) {
+		t.Fatalf("want %v, got %v", ErrTreeHash, err)
+	}
+}
+
+func TestTreeHashErrors(t *testing.T) {
+	data := make([]byte, 4096)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := TreeHashReaderAt(ctx, HashSHA2_256, bytes.NewReader(data), int64(len(data)), 512, 2); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+
+	// data is shorter than size
+	if _, err := TreeHashReaderAt(context.Background(), HashSHA2_256, bytes.NewReader(data), 5000, 512, 2); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+
+	th, err := TreeHashReaderAt(context.Background(), HashSHA2_256, bytes.NewReader(nil), 0, TreeHashChunkSize, 0)
+	if err != nil || len(th.Chunks) != 0 || th.Validate() != nil {
+		t.Fatalf("empty data: %v", err)
+	}
+
+	// huge chunk size doesn't allocate more than data size
+	th, err = TreeHashReaderAt(context.Background(), HashSHA2_256, bytes.NewReader(data), int64(len(data)), 1<<50, 4)
+	if err != nil || len(th.Chunks) != 1 {
+		t.Fatalf("huge chunk: %v", err)
+	}
+
+	for _, chunkSize := range []int64{0, -1} {
+		if _, err := TreeHashReaderAt(context.Background(), HashSHA2_256, bytes.NewReader(data), int64(len(data)), chunkSize, 0); err == nil {
+			t.Fatalf("chunk size %d accepted", chunkSize)
+		}
+	}
+}
+
+func BenchmarkTreeHash(b *testing.B) {
+	data := make([]byte, 64<<20)
+	r := bytes.NewReader(data)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		TreeHashReaderAt(context.Background(), HashSHA3_256, r, int64(len(data)), TreeHashChunkSize, 0)
+	}
+}
@@ -0,0 +1,395 @@
+// Command bhx manages bhx accounts and signs files.
+//
+// Usage:
+//
+//	bhx keygen -name NAME [-field key=value]... [-out DIR]
+//	bhx sign -key KEYFILE [-out SIGFILE] FILE
+//	bhx verify FILE SIGFILE PUBKEY|ACCOUNT.json
+//	bhx account show ACCOUNT.json
+//	bhx account verify ACCOUNT.json
+//
+// Key files are encrypted with passphrase from -pass-file or BHX_PASSPHRASE
+// environment variable. Signature file contains hex-encoded ed25519
+// signature of SHA3-256("bhx-file-sig" || SHA3-256(file)), so it can't
+// be reused as signature of account or other bhx data.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ma5ksh0w/gutil/bhx"
+)
+
+// Exit codes
+const (
+	exitOK = iota
+	exitFail
+	exitUsage
+)
+
+const usage = `usage:
+  bhx keygen -name NAME [-field key=value]... [-out DIR]
+  bhx sign -key KEYFILE [-out SIGFILE] FILE
+  bhx verify FILE SIGFILE PUBKEY|ACCOUNT.json
+  bhx account show ACCOUNT.json
+  bhx account verify ACCOUNT.json
+`
+
+// PassphraseEnv is environment variable with key file passphrase
+const PassphraseEnv = "BHX_PASSPHRASE"
+
+var errUsage = errors.New("invalid usage")
+
+var fileSigPrefix = []byte("bhx-file-sig")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes command and returns exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var err error
+	switch args[0] {
+	case "keygen":
+		err = keygen(args[1:], stdout)
+	case "sign":
+		err = sign(args[1:], stdout)
+	case "verify":
+		err = verify(args[1:], stdout)
+	case "account":
+		err = account(args[1:], stdout)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "bhx: %v\n", err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(stderr, usage)
+			return exitUsage
+		}
+
+		return exitFail
+	}
+
+	return exitOK
+}
+
+// fieldsFlag collects repeated key=value flags
+type fieldsFlag map[string]string
+
+func (f fieldsFlag) String() string { return "" }
+
+func (f fieldsFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("field %q must be key=value", s)
+	}
+
+	f[k] = v
+	return nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s: %v", errUsage, fs.Name(), err)
+	}
+
+	if fs.NArg() != nargs {
+		return fmt.Errorf("%w: %s needs %d arguments", errUsage, fs.Name(), nargs)
+	}
+
+	return nil
+}
+
+func passphrase(passFile string) (string, error) {
+	if passFile != "" {
+		data, err := os.ReadFile(passFile)
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return p, nil
+	}
+
+	return "", fmt.Errorf("passphrase is required, use -pass-file or %s", PassphraseEnv)
+}
+
+func keygen(args []string, stdout io.Writer) error {
+	fs := newFlagSet("keygen")
+	name := fs.String("name", "", "account name")
+	out := fs.String("out", ".", "output directory")
+	passFile := fs.String("pass-file", "", "passphrase file")
+	fields := fieldsFlag{}
+	fs.Var(fields, "field", "account field key=value (repeated)")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *name == "" || strings.ContainsAny(*name, `/\`) {
+		return fmt.Errorf("%w: keygen needs -name without path separators", errUsage)
+	}
+
+	passw, err := passphrase(*passFile)
+	if err != nil {
+		return err
+	}
+
+	acc, err := bhx.MakeNewAccount(*name)
+	if err != nil {
+		return err
+	}
+
+	acc.Fields = fields
+	pub, err := acc.SignAccount(nil)
+	if err != nil {
+		return err
+	}
+
+	keyJSON, err := acc.ExportJSON(passw)
+	if err != nil {
+		return err
+	}
+
+	pubJSON, err := pub.ExportJSON()
+	if err != nil {
+		return err
+	}
+
+	keyPath := filepath.Join(*out, *name+".key.json")
+	pubPath := filepath.Join(*out, *name+".json")
+	if err := writeNew(keyPath, keyJSON, 0600); err != nil {
+		return err
+	}
+
+	if err := writeNew(pubPath, pubJSON, 0644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "public key: %s\nkey file:   %s\naccount:    %s\n", pub.PublicKey(), keyPath, pubPath)
+	return nil
+}
+
+// writeNew writes file, which must not exist
+func writeNew(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func sign(args []string, stdout io.Writer) error {
+	fs := newFlagSet("sign")
+	keyFile := fs.String("key", "", "key file")
+	out := fs.String("out", "", "signature file (default FILE.sig)")
+	passFile := fs.String("pass-file", "", "passphrase file")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	if *keyFile == "" {
+		return fmt.Errorf("%w: sign needs -key", errUsage)
+	}
+
+	passw, err := passphrase(*passFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+
+	acc, err := new(bhx.MyAccount).ImportJSON(data, passw)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *keyFile, err)
+	}
+
+	path := fs.Arg(0)
+	hash, err := bhx.FileSha256(path)
+	if err != nil {
+		return err
+	}
+
+	sigPath := *out
+	if sigPath == "" {
+		sigPath = path + ".sig"
+	}
+
+	digest := fileDigest(hash)
+	sig := acc.Keys.Sign(digest[:])
+	if err := os.WriteFile(sigPath, []byte(sig.String()+"\n"), 0644); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s  %s\nsignature: %s\n", hash, path, sigPath)
+	return nil
+}
+
+func verify(args []string, stdout io.Writer) error {
+	fs := newFlagSet("verify")
+	if err := parseFlags(fs, args, 3); err != nil {
+		return err
+	}
+
+	path, sigPath, signer := fs.Arg(0), fs.Arg(1), fs.Arg(2)
+	pub, err := signerKey(signer)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(sigPath)
+	if err != nil {
+		return err
+	}
+
+	sig, err := bhx.ParseSigData(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", sigPath, err)
+	}
+
+	hash, err := bhx.FileSha256(path)
+	if err != nil {
+		return err
+	}
+
+	if digest := fileDigest(hash); !bhx.Verify(pub, digest[:], sig) {
+		return fmt.Errorf("%s: invalid signature", path)
+	}
+
+	fmt.Fprintf(stdout, "%s: signature OK, signed by %s\n", path, pub)
+	return nil
+}
+
+// fileDigest returns signed digest of file hash
+func fileDigest(hash bhx.Hash256) bhx.Hash256 {
+	return bhx.Sha256H(fileSigPrefix, hash[:])
+}
+
+// signerKey returns key from hex string or verified account file
+func signerKey(s string) (*bhx.PubKey, error) {
+	if _, err := os.Stat(s); err == nil {
+		acc, err := loadAccount(s)
+		if err != nil {
+			return nil, err
+		}
+
+		return acc.PublicKey(), nil
+	}
+
+	pub, err := bhx.ParsePubKey(s)
+	if err != nil {
+		return nil, fmt.Errorf("%q is neither account file nor public key: %w", s, err)
+	}
+
+	return pub, nil
+}
+
+// loadAccount reads and verifies account file
+func loadAccount(path string) (*bhx.Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := new(bhx.Account).ImportJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := acc.VerifyWith(nil); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return acc, nil
+}
+
+func account(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: account needs show or verify", errUsage)
+	}
+
+	fs := newFlagSet("account " + args[0])
+	if err := parseFlags(fs, args[1:], 1); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show":
+		return accountShow(fs.Arg(0), stdout)
+	case "verify":
+		acc, err := loadAccount(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "%s: account OK, %s\n", fs.Arg(0), acc.PublicKey())
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown account command %q", errUsage, args[0])
+	}
+}
+
+func accountShow(path string, stdout io.Writer) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	acc, err := new(bhx.Account).ImportJSON(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	status := "valid"
+	if err := acc.VerifyWith(nil); err != nil {
+		status = err.Error()
+	}
+
+	fmt.Fprintf(stdout, "name:       %s\n", acc.Name())
+	fmt.Fprintf(stdout, "public key: %s\n", acc.PublicKey())
+	fmt.Fprintf(stdout, "version:    %d\n", acc.Version())
+	fmt.Fprintf(stdout, "sequence:   %d\n", acc.Sequence())
+	fmt.Fprintf(stdout, "timestamp:  %s\n", time.Unix(int64(acc.Timestamp()), 0).UTC().Format(time.RFC3339))
+	fmt.Fprintf(stdout, "hash:       %s\n", acc.GetHash())
+	fmt.Fprintf(stdout, "signature:  %s\n", status)
+
+	for _, k := range acc.FieldNames() {
+		fmt.Fprintf(stdout, "field %s: %s\n", k, acc.Get(k))
+	}
+
+	return nil
+}
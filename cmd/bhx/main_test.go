@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ma5ksh0w/gutil/bhx"
+)
+
+func runCmd(t *testing.T, want int, args ...string) string {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	if code := run(args, &stdout, &stderr); code != want {
+		t.Fatalf("bhx %s: exit code %d, want %d\n%s", strings.Join(args, " "), code, want, stderr.String())
+	}
+
+	return stdout.String() + stderr.String()
+}
+
+func TestEndToEnd(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PassphraseEnv, "correct horse")
+
+	runCmd(t, exitOK, "keygen", "-name", "alice", "-field", "email=alice@example.com", "-out", dir)
+	runCmd(t, exitOK, "keygen", "-name", "bob", "-out", dir)
+	runCmd(t, exitFail, "keygen", "-name", "alice", "-out", dir)
+
+	keyFile := filepath.Join(dir, "alice.key.json")
+	accFile := filepath.Join(dir, "alice.json")
+	if fi, err := os.Stat(keyFile); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("key file: %v", err)
+	}
+
+	out := runCmd(t, exitOK, "account", "show", accFile)
+	if !strings.Contains(out, "name:       alice") || !strings.Contains(out, "field email: alice@example.com") {
+		t.Fatalf("unexpected account info:\n%s", out)
+	}
+
+	runCmd(t, exitOK, "account", "verify", accFile)
+
+	file := filepath.Join(dir, "release.tar")
+	if err := os.WriteFile(file, []byte("artifact"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCmd(t, exitOK, "sign", "-key", keyFile, file)
+	runCmd(t, exitOK, "verify", file, file+".sig", accFile)
+	runCmd(t, exitFail, "verify", file, file+".sig", filepath.Join(dir, "bob.json"))
+
+	// verify by hex key
+	pub := strings.Fields(strings.SplitN(out, "public key:", 2)[1])[0]
+	runCmd(t, exitOK, "verify", file, file+".sig", pub)
+
+	if err := os.WriteFile(file, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCmd(t, exitFail, "verify", file, file+".sig", accFile)
+
+	// wrong passphrase
+	t.Setenv(PassphraseEnv, "wrong")
+	runCmd(t, exitFail, "sign", "-key", keyFile, file)
+
+	// tampered account
+	data, _ := os.ReadFile(accFile)
+	data = bytes.Replace(data, []byte("alice@example.com"), []byte("mallory@example.com"), 1)
+	if err := os.WriteFile(accFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runCmd(t, exitFail, "account", "verify", accFile)
+	if out := runCmd(t, exitOK, "account", "show", accFile); !strings.Contains(out, "invalid signature") {
+		t.Fatalf("tampered account shown as valid:\n%s", out)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	runCmd(t, exitUsage)
+	runCmd(t, exitUsage, "frobnicate")
+	runCmd(t, exitUsage, "sign", "file")
+	runCmd(t, exitUsage, "verify", "file")
+	runCmd(t, exitUsage, "account", "list", "x")
+
+	t.Setenv(PassphraseEnv, "")
+	runCmd(t, exitFail, "keygen", "-name", "alice", "-out", t.TempDir())
+}
+
+func TestFileSigDomain(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PassphraseEnv, "correct horse")
+	runCmd(t, exitOK, "keygen", "-name", "alice", "-field", "email=alice@example.com", "-out", dir)
+
+	acc, err := loadAccount(filepath.Join(dir, "alice.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// file, which is preimage of account hash
+	proof, err := acc.FieldProof("email")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, val := bhx.Sha256H([]byte("email")), bhx.Sha256H([]byte("alice@example.com"))
+	fieldsRoot := bhx.MerkleRoot([]bhx.Hash256{bhx.Sha256H([]byte{0x00}, key[:], val[:])})
+	preimage := append([]byte{0x03}, proof.Header[:]...)
+	preimage = append(preimage, fieldsRoot[:]...)
+	preimage = append(preimage, 1, 0, 0, 0)
+
+	file := filepath.Join(dir, "account.bin")
+	if err := os.WriteFile(file, preimage, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	accHash := acc.GetHash()
+	if hash, _ := bhx.FileSha256(file); !hash.Equal(accHash) {
+		t.Fatal("file is not account hash preimage")
+	}
+
+	runCmd(t, exitOK, "sign", "-key", filepath.Join(dir, "alice.key.json"), file)
+	data, err := os.ReadFile(file + ".sig")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := bhx.ParseSigData(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bhx.Verify(acc.PublicKey(), accHash[:], sig) {
+		t.Fatal("file signature is valid account signature")
+	}
+}
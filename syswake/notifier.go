@@ -1,5 +1,10 @@
 package syswake
 
+import (
+	"os"
+	"os/signal"
+)
+
 // WakeSleepSignal describes Sleep/WakeUp signal state
 type WakeSleepSignal uint8
 
@@ -7,8 +12,8 @@ type WakeSleepSignal uint8
 const (
 	SigSleep  WakeSleepSignal = iota // Going sleep
 	SigWakeUp                        // Wake up
-	SigExit                          // Interrupt/Hangup signal
-	SigReload                        // Pause and WakeUp signal for daemon (not implemented)
+	SigExit                          // Interrupt/Terminate signal
+	SigReload                        // Reload signal for daemon (Hangup by default)
 )
 
 // WakeSleepNotifier notifies about wakeup/
@@ -16,3 +21,67 @@ type WakeSleepNotifier interface {
 	GetNotificationChannel() <-chan WakeSleepSignal
 	Close()
 }
+
+// SleepInhibitor is implemented by notifiers, which can delay system sleep
+// (see WithSleepDelay)
+type SleepInhibitor interface {
+	// ReadyToSleep releases the sleep delay lock after SigSleep is handled
+	ReadyToSleep()
+}
+
+// Option configures notifier
+type Option func(*options)
+
+type options struct {
+	signals       map[os.Signal]WakeSleepSignal
+	sleepDelay    bool
+	sleepDelayWhy string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{signals: defaultSignalMap()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithSleepDelay takes the sleep delay lock (logind "delay" inhibitor on
+// linux), so system waits before suspend until SleepInhibitor.ReadyToSleep
+// is called (or the system delay timeout expires). Ignored on platforms
+// without inhibitor support.
+func WithSleepDelay(why string) Option {
+	return func(o *options) {
+		o.sleepDelay = true
+		o.sleepDelayWhy = why
+	}
+}
+
+// WithSignalMap sets which OS signals are delivered and how they are
+// translated, it replaces the default map (SIGINT/SIGTERM to SigExit,
+// SIGHUP/SIGUSR1 to SigReload where available)
+func WithSignalMap(m map[os.Signal]WakeSleepSignal) Option {
+	return func(o *options) {
+		o.signals = make(map[os.Signal]WakeSleepSignal, len(m))
+		for sig, ws := range m {
+			o.signals[sig] = ws
+		}
+	}
+}
+
+// notifySignals relays mapped OS signals to returned channel
+func (o *options) notifySignals() chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	if len(o.signals) == 0 {
+		return ch
+	}
+
+	sigs := make([]os.Signal, 0, len(o.signals))
+	for sig := range o.signals {
+		sigs = append(sigs, sig)
+	}
+
+	signal.Notify(ch, sigs...)
+	return ch
+}
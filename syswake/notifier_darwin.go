@@ -12,16 +12,27 @@ import (
 	notif "github.com/prashantgupta24/mac-sleep-notifier/notifier"
 )
 
+func defaultSignalMap() map[os.Signal]WakeSleepSignal {
+	return map[os.Signal]WakeSleepSignal{
+		os.Interrupt:    SigExit,
+		syscall.SIGTERM: SigExit,
+		syscall.SIGHUP:  SigReload,
+		syscall.SIGUSR1: SigReload,
+	}
+}
+
 type notifier struct {
 	open      int32
 	notifChan chan WakeSleepSignal
 	close     chan struct{}
 	wg        sync.WaitGroup
+	opts      *options
 }
 
 // NewNotifier returns new syswake notifier
-func NewNotifier() WakeSleepNotifier {
-	return new(notifier)
+// (sleep delay option is not supported)
+func NewNotifier(opts ...Option) WakeSleepNotifier {
+	return &notifier{opts: newOptions(opts)}
 }
 
 func (c *notifier) GetNotificationChannel() <-chan WakeSleepSignal {
@@ -40,18 +51,16 @@ func (c *notifier) createNotificationChannel() {
 	c.notifChan = make(chan WakeSleepSignal, 1)
 	c.close = make(chan struct{})
 
+	// signals are subscribed before channel is returned
 	c.wg.Add(1)
-	go c.watch()
+	go c.watch(c.opts.notifySignals())
 }
 
-func (c *notifier) watch() {
+func (c *notifier) watch(ch chan os.Signal) {
 	defer c.wg.Done()
 	defer close(c.notifChan)
 	defer atomic.StoreInt32(&c.open, 0)
-
-	cn := make(chan os.Signal)
-	signal.Notify(cn, os.Interrupt, syscall.SIGINT, syscall.SIGHUP)
-	defer close(cn)
+	defer signal.Stop(ch)
 
 	wakeSleepCh := notif.GetInstance().Start()
 	defer notif.GetInstance().Quit()
@@ -61,19 +70,36 @@ func (c *notifier) watch() {
 		case <-c.close:
 			return
 
-		case _ = <-cn:
-			c.notifChan <- SigExit
+		case sig := <-ch:
+			if !c.notify(c.opts.signals[sig]) {
+				return
+			}
 
 		case act := <-wakeSleepCh:
+			ok := true
 			if act.Type == notif.Awake {
-				c.notifChan <- SigWakeUp
+				ok = c.notify(SigWakeUp)
 			} else if act.Type == notif.Sleep {
-				c.notifChan <- SigSleep
+				ok = c.notify(SigSleep)
+			}
+
+			if !ok {
+				return
 			}
 		}
 	}
 }
 
+// notify sends signal to consumer, returns false if notifier is closed
+func (c *notifier) notify(sig WakeSleepSignal) bool {
+	select {
+	case <-c.close:
+		return false
+	case c.notifChan <- sig:
+		return true
+	}
+}
+
 func (c *notifier) Close() {
 	if atomic.LoadInt32(&c.open) == 0 {
 		return
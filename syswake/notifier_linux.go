@@ -5,21 +5,47 @@ package syswake
 import (
 	"os"
 	"os/signal"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"syscall"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// systemd-logind D-Bus names
+const (
+	logindDest      = "org.freedesktop.login1"
+	logindPath      = "/org/freedesktop/login1"
+	logindManager   = "org.freedesktop.login1.Manager"
+	logindInhibit   = logindManager + ".Inhibit"
+	prepareForSleep = "PrepareForSleep"
 )
 
+func defaultSignalMap() map[os.Signal]WakeSleepSignal {
+	return map[os.Signal]WakeSleepSignal{
+		os.Interrupt:    SigExit,
+		syscall.SIGTERM: SigExit,
+		syscall.SIGHUP:  SigReload,
+		syscall.SIGUSR1: SigReload,
+	}
+}
+
 type notifier struct {
 	open      int32
 	notifChan chan WakeSleepSignal
 	close     chan struct{}
 	wg        sync.WaitGroup
+	opts      *options
+
+	mu   sync.Mutex
+	lock *os.File // logind delay inhibitor lock
 }
 
-// NewNotifier returns new syswake notifier
-func NewNotifier() WakeSleepNotifier {
-	return new(notifier)
+// NewNotifier returns new syswake notifier, sleep/wakeup signals
+// are received from systemd-logind over system D-Bus
+func NewNotifier(opts ...Option) WakeSleepNotifier {
+	return &notifier{opts: newOptions(opts)}
 }
 
 func (c *notifier) GetNotificationChannel() <-chan WakeSleepSignal {
@@ -38,30 +64,131 @@ func (c *notifier) createNotificationChannel() {
 	c.notifChan = make(chan WakeSleepSignal, 1)
 	c.close = make(chan struct{})
 
+	// signals are subscribed before channel is returned
 	c.wg.Add(1)
-	go c.watch()
+	go c.watch(c.opts.notifySignals())
 }
 
-func (c *notifier) watch() {
+func (c *notifier) watch(ch chan os.Signal) {
 	defer c.wg.Done()
 	defer close(c.notifChan)
 	defer atomic.StoreInt32(&c.open, 0)
-
-	ch := make(chan os.Signal)
-	signal.Notify(ch, os.Interrupt, syscall.SIGINT, syscall.SIGHUP)
-	defer close(ch)
+	defer c.ReadyToSleep()
+	defer signal.Stop(ch)
+
+	// without system bus only exit signals are delivered
+	var sleepCh chan *dbus.Signal
+	conn, err := c.subscribe()
+	if err == nil {
+		defer conn.Close()
+
+		sleepCh = make(chan *dbus.Signal, 4)
+		conn.Signal(sleepCh)
+		if c.opts.sleepDelay {
+			c.inhibit(conn)
+		}
+	}
 
 	for {
 		select {
 		case <-c.close:
 			return
 
-		case _ = <-ch:
-			c.notifChan <- SigExit
+		case sig := <-ch:
+			if !c.notify(c.opts.signals[sig]) {
+				return
+			}
+
+		case s := <-sleepCh:
+			if s == nil || s.Name != logindManager+"."+prepareForSleep || len(s.Body) == 0 {
+				continue
+			}
+
+			sleep, ok := s.Body[0].(bool)
+			if !ok {
+				continue
+			}
+
+			if sleep {
+				if !c.notify(SigSleep) {
+					return
+				}
+				continue
+			}
+
+			// lock is released before suspend, take it again
+			if c.opts.sleepDelay {
+				c.inhibit(conn)
+			}
+
+			if !c.notify(SigWakeUp) {
+				return
+			}
 		}
 	}
 }
 
+// notify sends signal to consumer, returns false if notifier is closed
+func (c *notifier) notify(sig WakeSleepSignal) bool {
+	select {
+	case <-c.close:
+		return false
+	case c.notifChan <- sig:
+		return true
+	}
+}
+
+// subscribe connects to system bus and subscribes to logind PrepareForSleep
+func (c *notifier) subscribe() (*dbus.Conn, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, err
+	}
+
+	err = conn.AddMatchSignal(
+		dbus.WithMatchSender(logindDest),
+		dbus.WithMatchObjectPath(logindPath),
+		dbus.WithMatchInterface(logindManager),
+		dbus.WithMatchMember(prepareForSleep),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// inhibit takes logind sleep delay lock
+func (c *notifier) inhibit(conn *dbus.Conn) {
+	var fd dbus.UnixFD
+	who := filepath.Base(os.Args[0])
+	err := conn.Object(logindDest, logindPath).
+		Call(logindInhibit, 0, "sleep", who, c.opts.sleepDelayWhy, "delay").
+		Store(&fd)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lock != nil {
+		c.lock.Close()
+	}
+
+	c.lock = os.NewFile(uintptr(fd), "logind-inhibit")
+}
+
+// ReadyToSleep releases logind sleep delay lock
+func (c *notifier) ReadyToSleep() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lock != nil {
+		c.lock.Close()
+		c.lock = nil
+	}
+}
+
 func (c *notifier) Close() {
 	if atomic.LoadInt32(&c.open) == 0 {
 		return
@@ -0,0 +1,191 @@
+//go:build linux
+
+package syswake
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeLogind is systemd-logind stand-in on a private dbus-daemon
+type fakeLogind struct {
+	conn     *dbus.Conn
+	inhibits chan *inhibitLock
+}
+
+// inhibitLock is pipe, which write end is passed to the client
+type inhibitLock struct {
+	r, w *os.File
+}
+
+func (l *fakeLogind) Inhibit(what, who, why, mode string) (dbus.UnixFD, *dbus.Error) {
+	if what != "sleep" || mode != "delay" {
+		return -1, dbus.MakeFailedError(os.ErrInvalid)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return -1, dbus.MakeFailedError(err)
+	}
+
+	// w is closed by the test after the reply is delivered
+	fd := dbus.UnixFD(w.Fd())
+	l.inhibits <- &inhibitLock{r: r, w: w}
+	return fd, nil
+}
+
+func (l *fakeLogind) prepareForSleep(t *testing.T, sleep bool) {
+	err := l.conn.Emit(logindPath, logindManager+"."+prepareForSleep, sleep)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func startBus(t *testing.T) string {
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--nopidfile", "--print-address=1")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(addr)
+}
+
+func startLogind(t *testing.T, addr string) *fakeLogind {
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	l := &fakeLogind{conn: conn, inhibits: make(chan *inhibitLock, 4)}
+	if err := conn.Export(l, logindPath, logindManager); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := conn.RequestName(logindDest, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("cannot own %s: %v", logindDest, err)
+	}
+
+	return l
+}
+
+func waitSignal(t *testing.T, ch <-chan WakeSleepSignal, want WakeSleepSignal) {
+	select {
+	case sig := <-ch:
+		if sig != want {
+			t.Fatalf("want signal %d, got %d", want, sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("signal %d timeout", want)
+	}
+}
+
+func waitInhibit(t *testing.T, l *fakeLogind) *inhibitLock {
+	select {
+	case lock := <-l.inhibits:
+		t.Cleanup(func() {
+			lock.r.Close()
+			lock.w.Close()
+		})
+		return lock
+	case <-time.After(5 * time.Second):
+		t.Fatal("inhibitor lock is not taken")
+	}
+
+	return nil
+}
+
+func TestLogindSleepWakeUp(t *testing.T) {
+	addr := startBus(t)
+	t.Setenv("DBUS_SYSTEM_BUS_ADDRESS", addr)
+	logind := startLogind(t, addr)
+
+	n := NewNotifier(WithSleepDelay("flush state"))
+	defer n.Close()
+
+	ch := n.GetNotificationChannel()
+	lock := waitInhibit(t, logind)
+
+	logind.prepareForSleep(t, true)
+	waitSignal(t, ch, SigSleep)
+
+	// lock is held until consumer is ready
+	lock.w.Close()
+	released := make(chan error, 1)
+	go func() {
+		_, err := lock.r.Read(make([]byte, 1))
+		released <- err
+	}()
+
+	select {
+	case <-released:
+		t.Fatal("inhibitor lock released before ReadyToSleep")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	n.(SleepInhibitor).ReadyToSleep()
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("inhibitor lock is not released")
+	}
+
+	logind.prepareForSleep(t, false)
+	waitSignal(t, ch, SigWakeUp)
+	waitInhibit(t, logind)
+}
+
+func TestNoSystemBus(t *testing.T) {
+	t.Setenv("DBUS_SYSTEM_BUS_ADDRESS", "unix:path=/nonexistent/bus")
+
+	n := NewNotifier()
+	n.GetNotificationChannel()
+	n.Close()
+}
+
+func TestSignalMap(t *testing.T) {
+	t.Setenv("DBUS_SYSTEM_BUS_ADDRESS", "unix:path=/nonexistent/bus")
+
+	n := NewNotifier()
+	ch := n.GetNotificationChannel()
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	waitSignal(t, ch, SigReload)
+	n.Close()
+
+	n = NewNotifier(WithSignalMap(map[os.Signal]WakeSleepSignal{
+		syscall.SIGUSR2: SigExit,
+	}))
+	defer n.Close()
+
+	ch = n.GetNotificationChannel()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	waitSignal(t, ch, SigExit)
+}
@@ -7,18 +7,28 @@ import (
 	"os/signal"
 	"sync"
 	"sync/atomic"
+	"syscall"
 )
 
+func defaultSignalMap() map[os.Signal]WakeSleepSignal {
+	return map[os.Signal]WakeSleepSignal{
+		os.Interrupt:    SigExit,
+		syscall.SIGTERM: SigExit,
+	}
+}
+
 type notifier struct {
 	open      int32
 	notifChan chan WakeSleepSignal
 	close     chan struct{}
 	wg        sync.WaitGroup
+	opts      *options
 }
 
 // NewNotifier returns new syswake notifier
-func NewNotifier() WakeSleepNotifier {
-	return new(notifier)
+// (sleep delay option is not supported)
+func NewNotifier(opts ...Option) WakeSleepNotifier {
+	return &notifier{opts: newOptions(opts)}
 }
 
 func (c *notifier) GetNotificationChannel() <-chan WakeSleepSignal {
@@ -37,30 +47,40 @@ func (c *notifier) createNotificationChannel() {
 	c.notifChan = make(chan WakeSleepSignal, 1)
 	c.close = make(chan struct{})
 
+	// signals are subscribed before channel is returned
 	c.wg.Add(1)
-	go c.watch()
+	go c.watch(c.opts.notifySignals())
 }
 
-func (c *notifier) watch() {
+func (c *notifier) watch(ch chan os.Signal) {
 	defer c.wg.Done()
 	defer close(c.notifChan)
 	defer atomic.StoreInt32(&c.open, 0)
-
-	ch := make(chan os.Signal)
-	signal.Notify(ch, os.Interrupt)
-	defer close(ch)
+	defer signal.Stop(ch)
 
 	for {
 		select {
 		case <-c.close:
 			return
 
-		case _ = <-ch:
-			c.notifChan <- SigExit
+		case sig := <-ch:
+			if !c.notify(c.opts.signals[sig]) {
+				return
+			}
 		}
 	}
 }
 
+// notify sends signal to consumer, returns false if notifier is closed
+func (c *notifier) notify(sig WakeSleepSignal) bool {
+	select {
+	case <-c.close:
+		return false
+	case c.notifChan <- sig:
+		return true
+	}
+}
+
 func (c *notifier) Close() {
 	if atomic.LoadInt32(&c.open) == 0 {
 		return